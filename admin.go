@@ -0,0 +1,351 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-03-19
+
+package log
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/fuyibing/log/v5/common"
+	"github.com/fuyibing/log/v5/configurer"
+	"github.com/fuyibing/log/v5/loggers"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	adminFlushTimeout = time.Second * 5
+)
+
+type (
+	// admin
+	// http handler for runtime operations, routed by suffix of request
+	// path, so it can be mounted at any prefix. Optional name parameter
+	// specify named logger or package prefix of logger-levels.
+	//
+	//   GET       /level?name=orders                    - current level
+	//   PUT, POST /level?name=orders&level=DEBUG&ttl=5m - change level, reverted after ttl
+	//   DELETE    /level?name=orders                    - remove level of name
+	//   GET       /stats                                - executors and config
+	//   POST      /flush?timeout=5s                     - flush buckets
+	admin struct {
+		sync.Mutex
+
+		manager *manager
+		reverts map[string]*adminRevert
+	}
+
+	adminLevel struct {
+		Expire *time.Time              `json:"expire,omitempty"`
+		Level  common.Level            `json:"level"`
+		Levels map[string]common.Level `json:"levels,omitempty"`
+		Name   string                  `json:"name,omitempty"`
+		Origin common.Level            `json:"origin,omitempty"`
+	}
+
+	adminRevert struct {
+		expire time.Time
+		origin common.Level
+		timer  *time.Timer
+	}
+
+	adminExecutor struct {
+		Dropped    int64  `json:"dropped"`
+		Failed     int64  `json:"failed"`
+		Kind       string `json:"kind"`
+		Name       string `json:"name"`
+		Redirected int64  `json:"redirected"`
+		Remained   int    `json:"remained"`
+		Sent       int64  `json:"sent"`
+	}
+)
+
+// AdminHandler
+// return http handler of global Manager, mount it on internal port only.
+//
+//   http.Handle("/debug/log/", log.AdminHandler())
+func AdminHandler() http.Handler { return Manager.AdminHandler() }
+
+// /////////////////////////////////////////////////////////////////////////////
+// Interface methods
+// /////////////////////////////////////////////////////////////////////////////
+
+func (o *admin) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch path := strings.TrimSuffix(r.URL.Path, "/"); {
+	case strings.HasSuffix(path, "/level") || path == "level":
+		switch name := r.URL.Query().Get("name"); r.Method {
+		case http.MethodGet:
+			o.reply(w, http.StatusOK, o.level(name))
+		case http.MethodDelete:
+			if name == "" {
+				o.fail(w, http.StatusBadRequest, fmt.Errorf("name required"))
+				return
+			}
+			o.setLevel(name, "", 0)
+			o.reply(w, http.StatusOK, o.level(name))
+		case http.MethodPost, http.MethodPut:
+			o.onLevel(w, r)
+		default:
+			o.fail(w, http.StatusMethodNotAllowed, fmt.Errorf("method not allowed: %s", r.Method))
+		}
+
+	case strings.HasSuffix(path, "/stats") || path == "stats":
+		if r.Method != http.MethodGet {
+			o.fail(w, http.StatusMethodNotAllowed, fmt.Errorf("method not allowed: %s", r.Method))
+			return
+		}
+		o.reply(w, http.StatusOK, o.stats())
+
+	case strings.HasSuffix(path, "/flush") || path == "flush":
+		if r.Method != http.MethodPost {
+			o.fail(w, http.StatusMethodNotAllowed, fmt.Errorf("method not allowed: %s", r.Method))
+			return
+		}
+		o.onFlush(w, r)
+
+	default:
+		o.fail(w, http.StatusNotFound, fmt.Errorf("not found: %s", r.URL.Path))
+	}
+}
+
+// /////////////////////////////////////////////////////////////////////////////
+// Event methods
+// /////////////////////////////////////////////////////////////////////////////
+
+func (o *admin) onFlush(w http.ResponseWriter, r *http.Request) {
+	timeout := adminFlushTimeout
+	if s := r.URL.Query().Get("timeout"); s != "" {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			o.fail(w, http.StatusBadRequest, fmt.Errorf("invalid timeout: %s", s))
+			return
+		}
+		timeout = d
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), timeout)
+	defer cancel()
+
+	if err := o.manager.Flush(ctx); err != nil {
+		o.fail(w, http.StatusGatewayTimeout, err)
+		return
+	}
+	o.reply(w, http.StatusOK, map[string]interface{}{"flushed": true})
+}
+
+func (o *admin) onLevel(w http.ResponseWriter, r *http.Request) {
+	var (
+		query = r.URL.Query()
+		req   = struct {
+			Level string `json:"level"`
+			Name  string `json:"name"`
+			TTL   string `json:"ttl"`
+		}{Level: query.Get("level"), Name: query.Get("name"), TTL: query.Get("ttl")}
+		ttl time.Duration
+	)
+
+	// Parse json body
+	// if query not specified.
+	if req.Level == "" && r.Body != nil {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			o.fail(w, http.StatusBadRequest, fmt.Errorf("invalid body: %v", err))
+			return
+		}
+	}
+
+	level := common.Level(req.Level).Upper()
+	if level.Int() == 0 {
+		o.fail(w, http.StatusBadRequest, fmt.Errorf("unknown level: %q", req.Level))
+		return
+	}
+	if req.TTL != "" {
+		d, err := time.ParseDuration(req.TTL)
+		if err != nil || d <= 0 {
+			o.fail(w, http.StatusBadRequest, fmt.Errorf("invalid ttl: %s", req.TTL))
+			return
+		}
+		ttl = d
+	}
+
+	o.setLevel(req.Name, level, ttl)
+	o.reply(w, http.StatusOK, o.level(req.Name))
+}
+
+// /////////////////////////////////////////////////////////////////////////////
+// Access methods
+// /////////////////////////////////////////////////////////////////////////////
+
+func (o *admin) fail(w http.ResponseWriter, code int, err error) {
+	o.reply(w, code, map[string]interface{}{"error": err.Error()})
+}
+
+// get
+// return level of name, global level if name is empty.
+func (o *admin) get(name string) common.Level {
+	if name == "" {
+		return o.manager.config.GetLoggerLevel()
+	}
+	return o.manager.config.GetLoggerLevels()[name]
+}
+
+func (o *admin) level(name string) adminLevel {
+	o.Lock()
+	defer o.Unlock()
+
+	res := adminLevel{Name: name}
+	if name == "" {
+		res.Level, res.Levels = o.manager.config.GetLoggerLevelRules()
+	} else {
+		res.Level = o.get(name)
+	}
+	if rv, ok := o.reverts[name]; ok {
+		expire := rv.expire
+		res.Expire, res.Origin = &expire, rv.origin
+	}
+	return res
+}
+
+func (o *admin) reply(w http.ResponseWriter, code int, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// sampler
+// return counts of sampler.
+func (o *admin) sampler(s *loggers.Sampler) map[string]interface{} {
+	return map[string]interface{}{"keys": s.Keys(), "suppressed": s.Suppressed()}
+}
+
+// sampling
+// return settings of logger sampling.
+func (o *admin) sampling(ls configurer.LoggerSampling) map[string]interface{} {
+	return map[string]interface{}{
+		"enable":     ls.GetEnable(),
+		"initial":    ls.GetInitial(),
+		"interval":   ls.GetInterval(),
+		"span":       ls.GetSpan(),
+		"thereafter": ls.GetThereafter(),
+	}
+}
+
+// set
+// change level of name, global level if name is empty. Level of name
+// removed if level is empty.
+func (o *admin) set(name string, level common.Level) {
+	if name == "" {
+		o.manager.config.Setter().SetLoggerLevel(level)
+	} else {
+		o.manager.config.Setter().SetLoggerLevelFor(name, level)
+	}
+}
+
+// setLevel
+// change level of name, reverted to origin level after ttl if greater
+// than zero. Pending revert cancelled by next change.
+func (o *admin) setLevel(name string, level common.Level, ttl time.Duration) {
+	o.Lock()
+	defer o.Unlock()
+
+	origin := o.get(name)
+	if rv, ok := o.reverts[name]; ok {
+		rv.timer.Stop()
+		delete(o.reverts, name)
+		origin = rv.origin
+	}
+
+	o.set(name, level)
+	common.InternalInfo("<%s> admin: logger level changed [name=%s][level=%s][ttl=%v]", o.manager.name, name, level, ttl)
+
+	if ttl <= 0 {
+		return
+	}
+
+	rv := &adminRevert{expire: time.Now().Add(ttl), origin: origin}
+	rv.timer = time.AfterFunc(ttl, func() {
+		o.Lock()
+		defer o.Unlock()
+
+		// Ignore
+		// if cancelled by next change.
+		if o.reverts[name] != rv {
+			return
+		}
+		delete(o.reverts, name)
+		o.set(name, rv.origin)
+		common.InternalInfo("<%s> admin: logger level reverted [name=%s][level=%s]", o.manager.name, name, rv.origin)
+	})
+	o.reverts[name] = rv
+}
+
+func (o *admin) stats() map[string]interface{} {
+	var (
+		c         = o.manager.config
+		executors = make([]adminExecutor, 0)
+		level     = o.level("")
+		ls        = c.GetLoggerSampling()
+	)
+
+	add := func(kind, name string, ex interface{}) {
+		item := adminExecutor{Kind: kind, Name: name}
+		if counter, ok := ex.(common.Counter); ok {
+			item.Dropped, item.Failed = counter.Dropped(), counter.Failed()
+			item.Redirected, item.Remained, item.Sent = counter.Redirected(), counter.Remained(), counter.Sent()
+		}
+		executors = append(executors, item)
+	}
+
+	if ex := o.manager.logger.GetExecutor(); ex != nil {
+		add("logger", ex.Processor().Name(), ex)
+	}
+	if ex := o.manager.tracer.GetExecutor(); ex != nil {
+		add("tracer", ex.Processor().Name(), ex)
+	}
+	if ex := o.manager.tracer.GetDeadLetter(); ex != nil {
+		add("dead-letter", ex.Processor().Name(), ex)
+	}
+	if ex := o.manager.tracer.GetFallback(); ex != nil {
+		add("fallback", ex.Processor().Name(), ex)
+	}
+
+	return map[string]interface{}{
+		"config": map[string]interface{}{
+			"bucket-batch":          c.GetBucketBatch(),
+			"bucket-capacity":       c.GetBucketCapacity(),
+			"bucket-concurrency":    c.GetBucketConcurrency(),
+			"bucket-frequency":      c.GetBucketFrequency(),
+			"logger-exporter":       c.GetLoggerExporter(),
+			"logger-level":          level.Level,
+			"logger-levels":         level.Levels,
+			"logger-sampling":       o.sampling(ls),
+			"open-tracing-sampled":  c.GetOpenTracingSampled(),
+			"open-tracing-span-id":  c.GetOpenTracingSpanId(),
+			"open-tracing-trace-id": c.GetOpenTracingTraceId(),
+			"tracer-exporter":       c.GetTracerExporter(),
+			"tracer-topic":          c.GetTracerTopic(),
+		},
+		"executors": executors,
+		"hooks": map[string]interface{}{
+			"dropped": o.manager.logger.HookDropped(),
+		},
+		"samplers": map[string]interface{}{
+			"logger": o.sampler(o.manager.logger.Sampler()),
+			"tracer": o.sampler(o.manager.tracer.Sampler()),
+		},
+	}
+}
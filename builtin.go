@@ -16,12 +16,15 @@
 package log
 
 import (
+	"github.com/fuyibing/log/v5/configurer"
 	"github.com/fuyibing/log/v5/loggers"
 	"github.com/fuyibing/log/v5/loggers/logger_file"
+	"github.com/fuyibing/log/v5/loggers/logger_memory"
 	"github.com/fuyibing/log/v5/loggers/logger_term"
 	"github.com/fuyibing/log/v5/tracers"
 	"github.com/fuyibing/log/v5/tracers/tracer_file"
 	"github.com/fuyibing/log/v5/tracers/tracer_jaeger"
+	"github.com/fuyibing/log/v5/tracers/tracer_memory"
 	"github.com/fuyibing/log/v5/tracers/tracer_term"
 	"github.com/fuyibing/log/v5/tracers/tracer_zipkin"
 )
@@ -29,17 +32,28 @@ import (
 var (
 	// builtinLoggers
 	// builtin executors for logger export.
-	builtinLoggers = map[string]func() loggers.Executor{
-		"file": logger_file.New,
-		"term": logger_term.New,
+	builtinLoggers = map[string]func(operator loggers.OperatorManager) loggers.Executor{
+		"file":   logger_file.NewWith,
+		"memory": func(_ loggers.OperatorManager) loggers.Executor { return logger_memory.New() },
+		"term":   logger_term.NewWith,
 	}
 
 	// builtinTracers
 	// builtin executors for tracer export.
-	builtinTracers = map[string]func() tracers.Executor{
-		"file":   tracer_file.New,
-		"jaeger": tracer_jaeger.New,
-		"term":   tracer_term.New,
-		"zipkin": tracer_zipkin.New,
+	builtinTracers = map[string]func(operator tracers.OperatorManager) tracers.Executor{
+		"file":   tracer_file.NewWith,
+		"jaeger": tracer_jaeger.NewWith,
+		"memory": func(_ tracers.OperatorManager) tracers.Executor { return tracer_memory.New() },
+		"term":   tracer_term.NewWith,
+		"zipkin": tracer_zipkin.NewWith,
 	}
 )
+
+func init() {
+	for name := range builtinLoggers {
+		configurer.RegisterLoggerExporter(name)
+	}
+	for name := range builtinTracers {
+		configurer.RegisterTracerExporter(name)
+	}
+}
@@ -0,0 +1,401 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-03-10
+
+package common
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+type (
+	// BatchOption
+	// expose batch configuration methods. Implemented by
+	// configurer.Configuration.
+	BatchOption interface {
+		GetBucketBatch() int
+		GetBucketConcurrency() int32
+		GetBucketFrequency() int
+	}
+
+	// Batcher
+	// batching engine shared by async executors. It owns bucket, ticker,
+	// concurrency limit and drain-on-stop, executors provide send function
+	// only. Context of send is cancelled when listening stopped or flush
+	// cancelled.
+	Batcher[T any] interface {
+		// Bucket
+		// return bucket of batcher.
+		Bucket() Bucket
+
+		// Drain
+		// pop items until bucket is empty and no batch in flight. Used as
+		// processor after handler.
+		Drain(ctx context.Context) (ignored bool)
+
+		// Dropped
+		// return total count of items dropped by bucket.
+		Dropped() int64
+
+		// Failed
+		// return total count of items which send failed.
+		Failed() int64
+
+		// Flush
+		// block until bucket is empty and no batch in flight, context error
+		// returned if cancelled or deadline exceeded.
+		Flush(ctx context.Context) error
+
+		// Listen
+		// pop items per frequency until context cancelled. Used as
+		// processor callback handler.
+		Listen(ctx context.Context) (ignored bool)
+
+		// Processing
+		// return count of batches in flight.
+		Processing() int32
+
+		// Publish
+		// items into bucket. Send immediately if not healthy.
+		Publish(items ...T) (err error)
+
+		// Redirected
+		// return total count of items given up and published to another
+		// executor, such as dead letter or fallback.
+		Redirected() int64
+
+		// Sent
+		// return total count of items sent successfully.
+		Sent() int64
+	}
+
+	// Counter
+	// implemented by async executors, report counts of items.
+	Counter interface {
+		Dropped() int64
+		Failed() int64
+		Redirected() int64
+		Remained() int
+		Sent() int64
+	}
+
+	// Flusher
+	// implemented by async executors, items in bucket can be flushed on
+	// demand.
+	Flusher interface {
+		Dropped() int64
+		Flush(ctx context.Context) error
+		Remained() int
+	}
+
+	// RedirectError
+	// returned by send function if items given up and published to another
+	// executor (eg. dead letter). Count items are counted as redirected,
+	// rest of batch as sent, or as failed if Failure is not nil.
+	RedirectError struct {
+		Count   int
+		Err     error
+		Failure error
+		Kind    string
+	}
+
+	batcher[T any] struct {
+		bucket       Bucket
+		ctx          atomic.Value
+		failed, sent int64
+		healthy      func() bool
+		name         string
+		option       BatchOption
+		pool         sync.Pool
+		processing   int32
+		redirected   int64
+		send         func(ctx context.Context, list ...T) error
+	}
+)
+
+func (o *RedirectError) Error() string {
+	if o.Failure != nil {
+		return o.Kind + ": " + o.Err.Error() + "; " + o.Failure.Error()
+	}
+	return o.Kind + ": " + o.Err.Error()
+}
+
+func (o *RedirectError) Unwrap() error { return o.Err }
+
+const (
+	batcherFlushInterval = time.Millisecond * 10
+)
+
+// NewBatcher
+// create and return Batcher component. Healthy return false if executor
+// is not running, then items are sent synchronously.
+func NewBatcher[T any](name string, bucket Bucket, option BatchOption, healthy func() bool, send func(ctx context.Context, list ...T) error) Batcher[T] {
+	return (&batcher[T]{
+		bucket: bucket, healthy: healthy, name: name,
+		option: option, send: send,
+	}).init()
+}
+
+// /////////////////////////////////////////////////////////////////////////////
+// Interface methods
+// /////////////////////////////////////////////////////////////////////////////
+
+func (o *batcher[T]) Bucket() Bucket    { return o.bucket }
+func (o *batcher[T]) Failed() int64     { return atomic.LoadInt64(&o.failed) }
+func (o *batcher[T]) Processing() int32 { return atomic.LoadInt32(&o.processing) }
+func (o *batcher[T]) Redirected() int64 { return atomic.LoadInt64(&o.redirected) }
+func (o *batcher[T]) Sent() int64       { return atomic.LoadInt64(&o.sent) }
+
+func (o *batcher[T]) Drain(_ context.Context) (ignored bool) {
+	// Context of after handler is cancelled already, flush without
+	// deadline, retry limited by deadline of policy. Stop on failure if failed batch queued again by bucket,
+	// items are kept on disk for next process.
+	var stop func() bool
+	if _, ok := o.bucket.(Acker); ok {
+		failed := o.Failed()
+		stop = func() bool { return o.Failed() != failed }
+	}
+	_ = o.flush(context.Background(), stop)
+
+	// Sync files
+	// if bucket stored on disk.
+	if c, ok := o.bucket.(io.Closer); ok {
+		if err := c.Close(); err != nil {
+			InternalInfo("<%s> bucket close: %v", o.name, err)
+		}
+	}
+	return
+}
+
+func (o *batcher[T]) Dropped() (total int64) {
+	if d, ok := o.bucket.(Dropper); ok {
+		for _, n := range d.Dropped() {
+			total += n
+		}
+	}
+	return
+}
+
+func (o *batcher[T]) Flush(ctx context.Context) error { return o.flush(ctx, nil) }
+
+func (o *batcher[T]) Listen(ctx context.Context) (ignored bool) {
+	InternalInfo("<%s> signal listening", o.name)
+	o.ctx.Store(ctx)
+
+	// 定时收取.
+	freq := o.option.GetBucketFrequency()
+	ti := time.NewTicker(time.Duration(freq) * time.Millisecond)
+	defer ti.Stop()
+
+	// 监听信号.
+	for {
+		select {
+		case <-ti.C:
+			// 频率变更.
+			if n := o.option.GetBucketFrequency(); n > 0 && n != freq {
+				freq = n
+				ti.Reset(time.Duration(freq) * time.Millisecond)
+			}
+			go o.pop(ctx)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (o *batcher[T]) Publish(items ...T) (err error) {
+	var total int
+
+	// 健康进程.
+	if o.healthy() {
+		// 数据入桶.
+		for _, item := range items {
+			if total, err = o.bucket.Add(item); err != nil {
+				return
+			}
+		}
+
+		// 立即消费.
+		if total >= o.option.GetBucketBatch() {
+			go o.pop(o.context())
+		}
+		return
+	}
+
+	// 立即发送.
+	return o.deliver(context.Background(), items...)
+}
+
+// /////////////////////////////////////////////////////////////////////////////
+// Access and constructor
+// /////////////////////////////////////////////////////////////////////////////
+
+// context
+// return context of listening, background context used if not listening
+// yet.
+func (o *batcher[T]) context() context.Context {
+	if ctx, ok := o.ctx.Load().(context.Context); ok {
+		return ctx
+	}
+	return context.Background()
+}
+
+// deliver
+// items by send function, count result. Redirected items are not failed,
+// batch is acknowledged unless rest of batch failed.
+func (o *batcher[T]) deliver(ctx context.Context, list ...T) error {
+	var (
+		err = o.send(ctx, list...)
+		n   = len(list)
+		re  *RedirectError
+	)
+
+	if errors.As(err, &re) {
+		if re.Count < n {
+			n = re.Count
+		}
+		atomic.AddInt64(&o.redirected, int64(n))
+		if re.Failure != nil {
+			atomic.AddInt64(&o.failed, int64(len(list)-n))
+			return re.Failure
+		}
+		atomic.AddInt64(&o.sent, int64(len(list)-n))
+		return nil
+	}
+
+	if err != nil {
+		atomic.AddInt64(&o.failed, int64(n))
+		return err
+	}
+	atomic.AddInt64(&o.sent, int64(n))
+	return nil
+}
+
+// flush
+// pop items until bucket is empty and no batch in flight, or stop returned
+// true and batches in flight finished.
+func (o *batcher[T]) flush(ctx context.Context, stop func() bool) error {
+	ti := time.NewTicker(batcherFlushInterval)
+	defer ti.Stop()
+
+	for {
+		// 处理完成.
+		// - 空数据桶
+		// - 并行降低.
+		//
+		// Bucket checked before processing, pop increase processing
+		// before items removed, so batch in flight is always seen.
+		empty := o.bucket.IsEmpty()
+		cc := atomic.LoadInt32(&o.processing)
+		stopped := stop != nil && stop()
+		if (empty || stopped) && cc == 0 {
+			return nil
+		}
+
+		// 加大并行.
+		if !stopped && cc < o.option.GetBucketConcurrency() {
+			go o.pop(ctx)
+		}
+
+		// 定时延后.
+		select {
+		case <-ti.C:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+}
+
+func (o *batcher[T]) init() *batcher[T] {
+	o.pool.New = func() interface{} {
+		buf := make([]interface{}, 0, o.option.GetBucketBatch())
+		return &buf
+	}
+	return o
+}
+
+func (o *batcher[T]) pop(ctx context.Context) {
+	// 限流控制.
+	if cc := atomic.AddInt32(&o.processing, 1); cc > o.option.GetBucketConcurrency() {
+		atomic.AddInt32(&o.processing, -1)
+		return
+	}
+
+	// 恢复并行.
+	defer atomic.AddInt32(&o.processing, -1)
+
+	// 取出数据, 直到数据桶为空.
+	for {
+		list, count, ack := o.popn()
+		if count == 0 {
+			return
+		}
+
+		var err error
+		if len(list) > 0 {
+			if err = o.deliver(ctx, list...); err != nil {
+				InternalInfo("<%s> send: %v", o.name, err)
+			}
+		}
+
+		// Acknowledge with result, items of failed batch
+		// queued again and retried on next round.
+		if ack != nil {
+			ack(err == nil)
+			if err != nil {
+				return
+			}
+		}
+	}
+}
+
+func (o *batcher[T]) popn() (list []T, count int, ack func(ok bool)) {
+	var (
+		items []interface{}
+		limit = o.option.GetBucketBatch()
+	)
+
+	// Pop with acknowledgement or into reused
+	// slice if bucket supported.
+	if ab, ok := o.bucket.(Acker); ok {
+		items, _, count, ack = ab.PopnAck(limit)
+	} else if bp, ok := o.bucket.(BatchPopper); ok {
+		buf := o.pool.Get().(*[]interface{})
+		defer func() {
+			// Release references
+			// before slice returned to pool.
+			for i := range items {
+				items[i] = nil
+			}
+			*buf = items[:0]
+			o.pool.Put(buf)
+		}()
+		items, _, count = bp.PopnTo(*buf, limit)
+	} else {
+		items, _, count = o.bucket.Popn(limit)
+	}
+
+	list = make([]T, 0, count)
+	for _, item := range items {
+		if v, ok := item.(T); ok {
+			list = append(list, v)
+		}
+	}
+	return
+}
@@ -0,0 +1,185 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-03-12
+
+package common
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+var (
+	ErrBreakerOpen = fmt.Errorf("circuit breaker is open")
+)
+
+type (
+	// BreakerState
+	// state of circuit breaker.
+	BreakerState string
+
+	// BreakerOption
+	// expose circuit breaker configuration methods. Implemented by
+	// configurer.CircuitBreaker.
+	BreakerOption interface {
+		// GetCooldown
+		// return duration of open state before a trial call allowed, in
+		// milliseconds.
+		GetCooldown() int
+
+		// GetEnable
+		// return false if breaker disabled, all calls allowed.
+		GetEnable() bool
+
+		// GetFailureThreshold
+		// return count of consecutive failures which open the breaker.
+		GetFailureThreshold() int
+	}
+
+	// Breaker
+	// circuit breaker for remote executors.
+	Breaker interface {
+		// Allow
+		// return true if call allowed. In half-open state, only one trial
+		// call allowed until its result reported.
+		Allow() bool
+
+		// Failure
+		// report call failed.
+		Failure()
+
+		// State
+		// return current state.
+		State() BreakerState
+
+		// Success
+		// report call succeed.
+		Success()
+	}
+
+	breaker struct {
+		sync.Mutex
+
+		failures int
+		name     string
+		openedAt time.Time
+		option   BreakerOption
+		state    BreakerState
+		trial    bool
+	}
+)
+
+const (
+	BreakerClosed   BreakerState = "closed"
+	BreakerHalfOpen BreakerState = "half-open"
+	BreakerOpen     BreakerState = "open"
+)
+
+// NewBreaker
+// create and return Breaker component.
+func NewBreaker(name string, option BreakerOption) Breaker {
+	return (&breaker{name: name, option: option}).init()
+}
+
+// /////////////////////////////////////////////////////////////////////////////
+// Interface methods
+// /////////////////////////////////////////////////////////////////////////////
+
+func (o *breaker) Allow() bool {
+	if !o.option.GetEnable() {
+		return true
+	}
+
+	o.Lock()
+	defer o.Unlock()
+
+	switch o.state {
+	case BreakerOpen:
+		// Keep open
+		// until cool-down passed.
+		if time.Since(o.openedAt) < time.Duration(o.option.GetCooldown())*time.Millisecond {
+			return false
+		}
+		o.change(BreakerHalfOpen)
+		o.trial = true
+		return true
+
+	case BreakerHalfOpen:
+		// Allow one trial call only.
+		if o.trial {
+			return false
+		}
+		o.trial = true
+		return true
+	}
+	return true
+}
+
+func (o *breaker) Failure() {
+	if !o.option.GetEnable() {
+		return
+	}
+
+	o.Lock()
+	defer o.Unlock()
+
+	o.failures++
+	o.trial = false
+
+	if o.state == BreakerHalfOpen || o.failures >= o.option.GetFailureThreshold() {
+		o.openedAt = time.Now()
+		o.change(BreakerOpen)
+	}
+}
+
+func (o *breaker) State() BreakerState {
+	o.Lock()
+	defer o.Unlock()
+
+	return o.state
+}
+
+func (o *breaker) Success() {
+	if !o.option.GetEnable() {
+		return
+	}
+
+	o.Lock()
+	defer o.Unlock()
+
+	o.failures = 0
+	o.trial = false
+	o.change(BreakerClosed)
+}
+
+// /////////////////////////////////////////////////////////////////////////////
+// Access and constructor
+// /////////////////////////////////////////////////////////////////////////////
+
+func (o *breaker) change(state BreakerState) {
+	if o.state == state {
+		return
+	}
+
+	InternalInfo(`<%s> circuit breaker [from="%s"][to="%s"][failures=%d]`,
+		o.name, o.state, state, o.failures,
+	)
+	o.state = state
+}
+
+func (o *breaker) init() *breaker {
+	o.state = BreakerClosed
+	return o
+}
@@ -18,13 +18,23 @@ package common
 import (
 	"fmt"
 	"sync"
+	"time"
 )
 
 var (
 	ErrBucketIsFull = fmt.Errorf("bucket is fully")
 )
 
+const (
+	BucketRing  BucketType = "ring"
+	BucketSlice BucketType = "slice"
+)
+
 type (
+	// BucketType
+	// decide which Bucket implementation used by executors.
+	BucketType string
+
 	// Bucket component used for memory queues. In this package, his role used
 	// to store loggers.Log and tracers.Span components. Async goroutines pop
 	// them then publish to specified adapters (eg. Kafka, Jaeger and so on).
@@ -59,11 +69,24 @@ type (
 
 		caches   []interface{}
 		capacity int
+
+		// Overflow handling.
+		drops   map[string]int64
+		policy  OverflowPolicy
+		timeout time.Duration
+		waiter  chan bool
 	}
 )
 
 func NewBucket(capacity int) Bucket { return (&bucket{capacity: capacity}).init() }
 
+// NewBucketWithPolicy
+// create and return Bucket component, policy decide which item dropped
+// when capacity reached. Timeout used by OverflowBlock only.
+func NewBucketWithPolicy(capacity int, policy OverflowPolicy, timeout time.Duration) Bucket {
+	return (&bucket{capacity: capacity, policy: policy, timeout: timeout}).init()
+}
+
 // /////////////////////////////////////////////////////////////////////////////
 // Interface methods
 // /////////////////////////////////////////////////////////////////////////////
@@ -74,8 +97,10 @@ func (o *bucket) Add(item interface{}) (total int, err error) {
 
 	if item != nil {
 		if total = len(o.caches) + 1; o.capacity > 0 && total > o.capacity {
-			err = ErrBucketIsFull
-			return
+			if err = o.overflow(item); err != nil {
+				return
+			}
+			total = len(o.caches) + 1
 		}
 	}
 
@@ -110,6 +135,10 @@ func (o *bucket) Popn(limit int) (items []interface{}, total, count int) {
 		return
 	}
 
+	// Wake up
+	// blocked producers.
+	defer o.notify()
+
 	if limit >= total {
 		count = total
 		items = o.caches[:]
@@ -136,6 +165,7 @@ func (o *bucket) SetCapacity(n int) Bucket {
 // /////////////////////////////////////////////////////////////////////////////
 
 func (o *bucket) init() *bucket {
+	o.drops = make(map[string]int64)
 	o.reset()
 	return o
 }
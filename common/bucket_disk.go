@@ -0,0 +1,672 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-03-07
+
+package common
+
+import (
+	"encoding/binary"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+var (
+	ErrBucketCorrupted = fmt.Errorf("bucket segment corrupted")
+)
+
+const (
+	diskCursorName    = "cursor"
+	diskHeaderSize    = 8
+	diskSegmentFormat = "%020d.seg"
+	diskSegmentSuffix = ".seg"
+)
+
+type (
+	// Acker
+	// implemented by bucket which keep popped items until acknowledged.
+	// Ack called by batcher with result of send, items of failed batch are
+	// queued again. Items never acknowledged are popped again after
+	// restart.
+	Acker interface {
+		PopnAck(limit int) (items []interface{}, total, count int, ack func(ok bool))
+	}
+
+	// Codec
+	// encode / decode items which spilled to disk.
+	Codec interface {
+		// Decode
+		// bytes into item (loggers.Log / tracers.Span).
+		Decode(data []byte) (item interface{}, err error)
+
+		// Encode
+		// item (loggers.Log / tracers.Span) into bytes.
+		Encode(item interface{}) (data []byte, err error)
+	}
+
+	// DiskOption
+	// expose disk bucket configuration methods. Implemented by
+	// configurer.BucketSpill.
+	DiskOption interface {
+		GetMaxSize() int64
+		GetPath() string
+		GetSegmentSize() int64
+	}
+
+	// diskBucket
+	// store items in memory, spill to segment files when memory queue is
+	// full. New items are spilled too until all spilled items popped, so
+	// order is kept.
+	//
+	// Each record in segment file is stored as:
+	//
+	//   +--------+--------+---------+
+	//   | length | crc32  | payload |
+	//   +--------+--------+---------+
+	//   | 4 byte | 4 byte | n byte  |
+	//   +--------+--------+---------+
+	diskBucket struct {
+		sync.Mutex
+
+		codec  Codec
+		count  int
+		dir    string
+		drops  int64
+		memory Bucket
+		name   string
+		option DiskOption
+		size   int64
+
+		// Segments, sorted by id.
+		segments []uint64
+
+		// Acknowledged position,
+		// saved to cursor file.
+		ackId     uint64
+		ackOffset int64
+		pending   []*diskPending
+
+		// Read position.
+		readFp     *os.File
+		readId     uint64
+		readOffset int64
+
+		// Write position.
+		writeFp   *os.File
+		writeId   uint64
+		writeSize int64
+	}
+
+	// diskPending
+	// read position after a batch popped from disk.
+	diskPending struct {
+		done   bool
+		id     uint64
+		offset int64
+	}
+)
+
+// NewDiskBucket
+// create and return Bucket component which spill items to disk when memory
+// bucket is full. Records spilled but never acknowledged in previous
+// process are recovered at startup. Memory bucket returned if disk is not
+// available.
+func NewDiskBucket(name string, memory Bucket, option DiskOption, codec Codec) Bucket {
+	o := &diskBucket{
+		codec: codec, memory: memory, name: name, option: option,
+		dir: filepath.Join(option.GetPath(), name),
+	}
+
+	if err := o.init(); err != nil {
+		InternalInfo("<%s> disk bucket disabled: %v", name, err)
+		return o.memory
+	}
+	return o
+}
+
+// /////////////////////////////////////////////////////////////////////////////
+// Interface methods
+// /////////////////////////////////////////////////////////////////////////////
+
+func (o *diskBucket) Add(item interface{}) (total int, err error) {
+	o.Lock()
+	defer o.Unlock()
+
+	// Keep order
+	// if spilled items not popped.
+	if o.count == 0 {
+		if total, err = o.memory.Add(item); err != ErrBucketIsFull {
+			total += o.count
+			return
+		}
+	}
+
+	if err = o.spill(item); err != nil {
+		if err == ErrBucketIsFull {
+			o.drops++
+		}
+		return
+	}
+
+	total = o.memory.Count() + o.count
+	return
+}
+
+// Close
+// save acknowledged position, sync and close segment files. Files are
+// opened again if bucket used after closed.
+func (o *diskBucket) Close() (err error) {
+	o.Lock()
+	defer o.Unlock()
+
+	o.closeReader()
+	if err = o.save(); err != nil {
+		return
+	}
+	return o.closeWriter()
+}
+
+func (o *diskBucket) Count() int {
+	o.Lock()
+	defer o.Unlock()
+
+	return o.memory.Count() + o.count
+}
+
+// Dropped
+// return dropped counters of memory bucket and rejected counter of disk.
+func (o *diskBucket) Dropped() map[string]int64 {
+	res := make(map[string]int64)
+	if d, ok := o.memory.(Dropper); ok {
+		res = d.Dropped()
+	}
+
+	o.Lock()
+	defer o.Unlock()
+
+	if o.drops > 0 {
+		res[fmt.Sprintf("spill/%s", dropReasonFull)] = o.drops
+	}
+	return res
+}
+
+func (o *diskBucket) IsEmpty() bool {
+	return 0 == o.Count()
+}
+
+func (o *diskBucket) Pop() (item interface{}, exists bool) {
+	if items, _, count := o.Popn(1); count == 1 {
+		item = items[0]
+		exists = true
+	}
+	return
+}
+
+// Popn
+// pop items and acknowledge immediately.
+func (o *diskBucket) Popn(limit int) (items []interface{}, total, count int) {
+	var ack func(ok bool)
+	if items, total, count, ack = o.PopnAck(limit); ack != nil {
+		ack(true)
+	}
+	return
+}
+
+// PopnAck
+// pop items, read position of spilled items saved when acknowledged. Items
+// spilled to end of segments if not ok, so they are popped again and kept
+// on restart. Ack is nil if no item popped.
+func (o *diskBucket) PopnAck(limit int) (items []interface{}, total, count int, ack func(ok bool)) {
+	o.Lock()
+	defer o.Unlock()
+
+	// Pop from memory first, items in memory are
+	// older than spilled items.
+	if items, total, count = o.memory.Popn(limit); o.count == 0 || count >= limit {
+		total += o.count
+		if count > 0 {
+			ack = o.acker(nil, items)
+		}
+		return
+	}
+
+	total += o.count
+	if items == nil {
+		items = make([]interface{}, 0)
+	}
+
+	for count < limit && o.count > 0 {
+		item, err := o.read()
+		if err != nil {
+			InternalInfo("<%s> disk bucket read: %v", o.name, err)
+			o.discard()
+			if count > 0 {
+				ack = o.acker(nil, items)
+			}
+			return
+		}
+		o.count--
+		if item != nil {
+			items = append(items, item)
+			count++
+		}
+	}
+
+	p := &diskPending{id: o.readId, offset: o.readOffset}
+	o.pending = append(o.pending, p)
+	ack = o.acker(p, items)
+	return
+}
+
+func (o *diskBucket) SetCapacity(n int) Bucket {
+	o.memory.SetCapacity(n)
+	return o
+}
+
+// /////////////////////////////////////////////////////////////////////////////
+// Access and constructor
+// /////////////////////////////////////////////////////////////////////////////
+
+// acker
+// return ack function of popped items. Pending read position marked done
+// on both results, so acknowledged position never stalls on failed batch.
+func (o *diskBucket) acker(p *diskPending, items []interface{}) func(ok bool) {
+	var once sync.Once
+	return func(ok bool) {
+		once.Do(func() {
+			o.Lock()
+			defer o.Unlock()
+
+			if !ok {
+				o.requeue(items)
+			}
+			if p != nil {
+				p.done = true
+				if err := o.commit(); err != nil {
+					InternalInfo("<%s> disk bucket commit: %v", o.name, err)
+				}
+			}
+		})
+	}
+}
+
+// commit
+// move acknowledged position over batches acknowledged in order, remove
+// consumed segments.
+func (o *diskBucket) commit() (err error) {
+	n := 0
+	for ; n < len(o.pending) && o.pending[n].done; n++ {
+		o.ackId, o.ackOffset = o.pending[n].id, o.pending[n].offset
+	}
+	o.pending = o.pending[n:]
+
+	for len(o.segments) > 0 && o.segments[0] < o.ackId {
+		o.remove(o.segments[0])
+		o.segments = o.segments[1:]
+	}
+
+	// All spilled items acknowledged, reuse write segment from
+	// beginning. Cursor saved before truncated, so offset never beyond
+	// segment size.
+	if o.count == 0 && len(o.pending) == 0 && o.writeFp != nil && o.ackId == o.writeId {
+		o.ackOffset = 0
+		if err = o.save(); err != nil {
+			return
+		}
+		o.closeReader()
+		if err = o.writeFp.Truncate(0); err != nil {
+			return
+		}
+		o.size -= o.writeSize
+		o.readOffset = 0
+		o.writeSize = 0
+		return
+	}
+	return o.save()
+}
+
+func (o *diskBucket) closeReader() {
+	if o.readFp != nil {
+		_ = o.readFp.Close()
+		o.readFp = nil
+	}
+}
+
+func (o *diskBucket) closeWriter() (err error) {
+	if o.writeFp != nil {
+		if err = o.writeFp.Sync(); err == nil {
+			err = o.writeFp.Close()
+		} else {
+			_ = o.writeFp.Close()
+		}
+		o.writeFp = nil
+	}
+	return
+}
+
+// discard
+// all spilled items if segment corrupted.
+func (o *diskBucket) discard() {
+	o.closeReader()
+	for _, id := range o.segments {
+		if id != o.writeId {
+			o.remove(id)
+		}
+	}
+
+	o.count = 0
+	o.pending = nil
+	o.segments = []uint64{o.writeId}
+	o.ackId, o.ackOffset = o.writeId, o.writeSize
+	o.readId, o.readOffset = o.writeId, o.writeSize
+
+	if err := o.save(); err != nil {
+		InternalInfo("<%s> disk bucket commit: %v", o.name, err)
+	}
+}
+
+func (o *diskBucket) init() (err error) {
+	if err = os.MkdirAll(o.dir, 0755); err != nil {
+		return
+	}
+
+	// List segment files.
+	var list []os.DirEntry
+	if list, err = os.ReadDir(o.dir); err != nil {
+		return
+	}
+	for _, e := range list {
+		if s := e.Name(); strings.HasSuffix(s, diskSegmentSuffix) {
+			if id, pe := strconv.ParseUint(strings.TrimSuffix(s, diskSegmentSuffix), 10, 64); pe == nil {
+				o.segments = append(o.segments, id)
+			}
+		}
+	}
+	sort.Slice(o.segments, func(i, j int) bool { return o.segments[i] < o.segments[j] })
+
+	// Read cursor saved by previous process.
+	if buf, re := os.ReadFile(filepath.Join(o.dir, diskCursorName)); re == nil {
+		_, _ = fmt.Sscanf(string(buf), "%d %d", &o.readId, &o.readOffset)
+	}
+	if len(o.segments) > 0 && o.readId < o.segments[0] {
+		o.readId, o.readOffset = o.segments[0], 0
+	}
+
+	// Recover records
+	// which spilled but never acknowledged.
+	if err = o.recover(); err != nil {
+		return
+	}
+
+	o.ackId, o.ackOffset = o.readId, o.readOffset
+
+	if o.count > 0 {
+		InternalInfo("<%s> disk bucket recovered: %d items", o.name, o.count)
+	}
+	return
+}
+
+func (o *diskBucket) openWriter(id uint64) (err error) {
+	if err = o.closeWriter(); err != nil {
+		return
+	}
+
+	var fi os.FileInfo
+	if o.writeFp, err = os.OpenFile(o.path(id), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644); err != nil {
+		return
+	}
+	if fi, err = o.writeFp.Stat(); err != nil {
+		return
+	}
+
+	if n := len(o.segments); n == 0 || o.segments[n-1] != id {
+		o.segments = append(o.segments, id)
+	}
+
+	o.writeId = id
+	o.writeSize = fi.Size()
+	return
+}
+
+func (o *diskBucket) path(id uint64) string {
+	return filepath.Join(o.dir, fmt.Sprintf(diskSegmentFormat, id))
+}
+
+// read
+// next record from read position. Nil item returned if record can not be
+// decoded.
+func (o *diskBucket) read() (item interface{}, err error) {
+	for {
+		if o.readFp == nil {
+			if o.readFp, err = os.Open(o.path(o.readId)); err != nil {
+				return
+			}
+		}
+
+		// Move to next segment
+		// if end of current segment reached.
+		if o.readId != o.writeId && o.readOffset >= o.segmentSize(o.readId) {
+			o.closeReader()
+			if o.readId, o.readOffset = o.readId+1, 0; o.readId > o.writeId {
+				return nil, ErrBucketCorrupted
+			}
+			continue
+		}
+		break
+	}
+
+	var data []byte
+	if data, err = o.readFrame(o.readFp, o.readOffset, o.segmentSize(o.readId)); err != nil {
+		return
+	}
+
+	o.readOffset += int64(diskHeaderSize + len(data))
+	if item, err = o.codec.Decode(data); err != nil {
+		InternalInfo("<%s> disk bucket decode: %v", o.name, err)
+		item, err = nil, nil
+	}
+	return
+}
+
+// readFrame
+// read record at offset. Length in header must not exceed segment limit
+// and bytes left in file, otherwise header is corrupted.
+func (o *diskBucket) readFrame(fp *os.File, offset, size int64) (data []byte, err error) {
+	if offset >= size {
+		return nil, io.EOF
+	}
+	if offset+diskHeaderSize > size {
+		return nil, io.ErrUnexpectedEOF
+	}
+
+	header := make([]byte, diskHeaderSize)
+	if _, err = fp.ReadAt(header, offset); err != nil {
+		return
+	}
+
+	n := int64(binary.BigEndian.Uint32(header[0:4]))
+	if max := o.option.GetSegmentSize(); (max > 0 && n > max) || n > size-offset-diskHeaderSize {
+		return nil, ErrBucketCorrupted
+	}
+
+	data = make([]byte, n)
+	if _, err = fp.ReadAt(data, offset+diskHeaderSize); err != nil {
+		return
+	}
+	if crc32.ChecksumIEEE(data) != binary.BigEndian.Uint32(header[4:8]) {
+		err = ErrBucketCorrupted
+	}
+	return
+}
+
+// recover
+// count records from read position, truncate incomplete record at the
+// end of segment which written when process crashed. Read position is
+// clamped to segment size.
+func (o *diskBucket) recover() (err error) {
+	for _, id := range o.segments {
+		if id < o.readId {
+			o.remove(id)
+			continue
+		}
+
+		var (
+			fi     os.FileInfo
+			fp     *os.File
+			offset int64
+		)
+		if fp, err = os.OpenFile(o.path(id), os.O_RDWR, 0644); err != nil {
+			return
+		}
+		if fi, err = fp.Stat(); err != nil {
+			_ = fp.Close()
+			return
+		}
+
+		size := fi.Size()
+		if id == o.readId {
+			if o.readOffset > size {
+				o.readOffset = size
+			}
+			offset = o.readOffset
+		}
+
+		for {
+			data, re := o.readFrame(fp, offset, size)
+			if re != nil {
+				if re != io.EOF {
+					InternalInfo("<%s> disk bucket truncate: segment=%d, offset=%d, %v", o.name, id, offset, re)
+					_ = fp.Truncate(offset)
+				}
+				break
+			}
+			offset += int64(diskHeaderSize + len(data))
+			o.count++
+		}
+
+		o.size += offset
+		_ = fp.Close()
+	}
+
+	// Drop removed segments.
+	for len(o.segments) > 0 && o.segments[0] < o.readId {
+		o.segments = o.segments[1:]
+	}
+
+	// Continue writing at last segment.
+	id := o.readId
+	if n := len(o.segments); n > 0 {
+		id = o.segments[n-1]
+	}
+	return o.openWriter(id)
+}
+
+func (o *diskBucket) remove(id uint64) {
+	if fi, err := os.Stat(o.path(id)); err == nil {
+		o.size -= fi.Size()
+	}
+	if err := os.Remove(o.path(id)); err != nil && !os.IsNotExist(err) {
+		InternalInfo("<%s> disk bucket remove: %v", o.name, err)
+	}
+}
+
+// requeue
+// spill items of failed batch, popped again after spilled items. Items
+// dropped if disk is full.
+func (o *diskBucket) requeue(items []interface{}) {
+	for _, item := range items {
+		if err := o.spill(item); err != nil {
+			if err == ErrBucketIsFull {
+				o.drops++
+			}
+			InternalInfo("<%s> disk bucket requeue: %v", o.name, err)
+		}
+	}
+}
+
+// save
+// acknowledged position into cursor file.
+func (o *diskBucket) save() (err error) {
+	var fp *os.File
+	tmp := filepath.Join(o.dir, diskCursorName+".tmp")
+	if fp, err = os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0644); err != nil {
+		return
+	}
+	if _, err = fmt.Fprintf(fp, "%d %d", o.ackId, o.ackOffset); err == nil {
+		err = fp.Sync()
+	}
+	if ce := fp.Close(); err == nil {
+		err = ce
+	}
+	if err != nil {
+		return
+	}
+	return os.Rename(tmp, filepath.Join(o.dir, diskCursorName))
+}
+
+func (o *diskBucket) segmentSize(id uint64) int64 {
+	if id == o.writeId {
+		return o.writeSize
+	}
+	if fi, err := os.Stat(o.path(id)); err == nil {
+		return fi.Size()
+	}
+	return 0
+}
+
+// spill
+// item into write segment.
+func (o *diskBucket) spill(item interface{}) (err error) {
+	var data []byte
+	if data, err = o.codec.Encode(item); err != nil {
+		return
+	}
+
+	n := int64(diskHeaderSize + len(data))
+	if max := o.option.GetMaxSize(); max > 0 && o.size+n > max {
+		return ErrBucketIsFull
+	}
+
+	// Rotate segment,
+	// previous segment synced when closed.
+	if o.writeSize > 0 && o.writeSize+n > o.option.GetSegmentSize() {
+		if err = o.openWriter(o.writeId + 1); err != nil {
+			return
+		}
+	} else if o.writeFp == nil {
+		if err = o.openWriter(o.writeId); err != nil {
+			return
+		}
+	}
+
+	buf := make([]byte, n)
+	binary.BigEndian.PutUint32(buf[0:4], uint32(len(data)))
+	binary.BigEndian.PutUint32(buf[4:8], crc32.ChecksumIEEE(data))
+	copy(buf[diskHeaderSize:], data)
+
+	if _, err = o.writeFp.Write(buf); err != nil {
+		return
+	}
+
+	o.count++
+	o.size += n
+	o.writeSize += n
+	return
+}
@@ -0,0 +1,247 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-03-07
+
+package common
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+)
+
+type (
+	testBatchOption struct{}
+	testCodec       struct{}
+	testDiskOption  struct {
+		max  int64
+		path string
+	}
+)
+
+func (testBatchOption) GetBucketBatch() int         { return 3 }
+func (testBatchOption) GetBucketConcurrency() int32 { return 1 }
+func (testBatchOption) GetBucketFrequency() int     { return 10 }
+
+func (testCodec) Decode(data []byte) (interface{}, error) { return string(data), nil }
+func (testCodec) Encode(item interface{}) ([]byte, error) { return []byte(item.(string)), nil }
+
+func (o testDiskOption) GetMaxSize() int64     { return o.max }
+func (o testDiskOption) GetPath() string       { return o.path }
+func (o testDiskOption) GetSegmentSize() int64 { return 20 }
+
+func testDiskBucket(t *testing.T, path string) Bucket {
+	return testDiskBucketWith(t, testDiskOption{path: path})
+}
+
+func testDiskBucketWith(t *testing.T, option testDiskOption) Bucket {
+	b := NewDiskBucket("test", NewBucket(2), option, testCodec{})
+	if _, ok := b.(*diskBucket); !ok {
+		t.Fatalf("disk bucket not created")
+	}
+	return b
+}
+
+func testDiskAdd(t *testing.T, b Bucket, items ...string) {
+	for _, item := range items {
+		if _, err := b.Add(item); err != nil {
+			t.Fatalf("add %q: %v", item, err)
+		}
+	}
+}
+
+func TestDiskBucketSpill(t *testing.T) {
+	b := testDiskBucket(t, t.TempDir())
+	testDiskAdd(t, b, "a", "b", "c", "d", "e", "f", "g")
+
+	if n := b.Count(); n != 7 {
+		t.Fatalf("count: expect 7, got %d", n)
+	}
+	if n := len(b.(*diskBucket).segments); n < 2 {
+		t.Fatalf("segments: expect rotated, got %d", n)
+	}
+
+	items, _, count := b.Popn(10)
+	if expect := []interface{}{"a", "b", "c", "d", "e", "f", "g"}; count != 7 || !reflect.DeepEqual(items, expect) {
+		t.Fatalf("popn: expect %v, got %v", expect, items)
+	}
+	if !b.IsEmpty() {
+		t.Fatalf("bucket not empty after popped")
+	}
+}
+
+func TestDiskBucketRecover(t *testing.T) {
+	dir := t.TempDir()
+	b := testDiskBucket(t, dir)
+	testDiskAdd(t, b, "a", "b", "c", "d", "e")
+
+	// Acknowledged.
+	_, _, _, ack := b.(Acker).PopnAck(3)
+	ack(true)
+
+	// Not acknowledged,
+	// send failed.
+	if items, _, _, _ := b.(Acker).PopnAck(1); !reflect.DeepEqual(items, []interface{}{"d"}) {
+		t.Fatalf("popn: expect [d], got %v", items)
+	}
+	if err := b.(*diskBucket).Close(); err != nil {
+		t.Fatalf("close: %v", err)
+	}
+
+	b = testDiskBucket(t, dir)
+	items, _, count := b.Popn(10)
+	if expect := []interface{}{"d", "e"}; count != 2 || !reflect.DeepEqual(items, expect) {
+		t.Fatalf("recover: expect %v, got %v", expect, items)
+	}
+}
+
+func TestDiskBucketRecoverClamp(t *testing.T) {
+	dir := t.TempDir()
+	b := testDiskBucket(t, dir)
+	testDiskAdd(t, b, "a", "b", "c")
+	_ = b.(*diskBucket).Close()
+
+	// Segment truncated after cursor saved.
+	if err := os.WriteFile(filepath.Join(dir, "test", diskCursorName), []byte("0 9999"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	b = testDiskBucket(t, dir)
+	if o := b.(*diskBucket); o.readOffset != o.writeSize || o.count != 0 {
+		t.Fatalf("clamp: offset=%d, size=%d, count=%d", o.readOffset, o.writeSize, o.count)
+	}
+
+	testDiskAdd(t, b, "d", "e", "f")
+	if items, _, _ := b.Popn(10); !reflect.DeepEqual(items, []interface{}{"d", "e", "f"}) {
+		t.Fatalf("popn: expect [d e f], got %v", items)
+	}
+}
+
+func TestDiskBucketCorrupted(t *testing.T) {
+	dir := t.TempDir()
+	b := testDiskBucket(t, dir)
+	testDiskAdd(t, b, "a", "b", "c", "d")
+	_ = b.(*diskBucket).Close()
+
+	// Append header with length beyond segment limit.
+	seg := filepath.Join(dir, "test", "00000000000000000000.seg")
+	fp, err := os.OpenFile(seg, os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	header := make([]byte, diskHeaderSize)
+	binary.BigEndian.PutUint32(header[0:4], 0xFFFFFFF0)
+	_, _ = fp.Write(header)
+	_ = fp.Close()
+
+	b = testDiskBucket(t, dir)
+	if n := b.Count(); n != 2 {
+		t.Fatalf("truncate: expect 2 recovered, got %d", n)
+	}
+
+	// Flip payload byte,
+	// crc mismatched.
+	_ = b.(*diskBucket).Close()
+	buf, _ := os.ReadFile(seg)
+	buf[diskHeaderSize] ^= 0xFF
+	_ = os.WriteFile(seg, buf, 0644)
+
+	b = testDiskBucket(t, dir)
+	if n := b.Count(); n != 0 {
+		t.Fatalf("crc: expect 0 recovered, got %d", n)
+	}
+
+	testDiskAdd(t, b, "e", "f", "g")
+	if items, _, _ := b.Popn(10); !reflect.DeepEqual(items, []interface{}{"e", "f", "g"}) {
+		t.Fatalf("popn: expect [e f g], got %v", items)
+	}
+}
+
+func TestDiskBucketNack(t *testing.T) {
+	b := testDiskBucketWith(t, testDiskOption{max: 200, path: t.TempDir()})
+	testDiskAdd(t, b, "a", "b", "c", "d", "e")
+
+	// Failed batch
+	// queued again.
+	items, _, _, ack := b.(Acker).PopnAck(3)
+	ack(false)
+	if n := b.Count(); n != 5 {
+		t.Fatalf("nack: expect 5 remained, got %d", n)
+	}
+
+	// Consumer keeps up.
+	for i := 0; i < 50; i++ {
+		testDiskAdd(t, b, "x")
+		if _, _, count := b.Popn(10); count == 0 {
+			t.Fatalf("popn: nothing popped")
+		}
+	}
+
+	o := b.(*diskBucket)
+	if len(o.segments) != 1 || len(o.pending) != 0 || o.size != 0 || o.drops != 0 {
+		t.Fatalf("reclaim: segments=%d, pending=%d, size=%d, dropped=%d", len(o.segments), len(o.pending), o.size, o.drops)
+	}
+	if len(items) != 3 {
+		t.Fatalf("popn: expect 3 items, got %v", items)
+	}
+}
+
+func TestDiskBucketRedeliver(t *testing.T) {
+	var (
+		fails    = 3
+		mu       sync.Mutex
+		received []string
+	)
+
+	bucket := testDiskBucket(t, t.TempDir())
+	batcher := NewBatcher[string]("test", bucket, testBatchOption{}, func() bool { return true }, func(_ context.Context, list ...string) error {
+		mu.Lock()
+		defer mu.Unlock()
+
+		// Backend unavailable
+		// for first sends.
+		if fails > 0 {
+			fails--
+			return errors.New("unavailable")
+		}
+		received = append(received, list...)
+		return nil
+	})
+
+	expect := []string{"a", "b", "c", "d", "e", "f", "g", "h"}
+	if err := batcher.Publish(expect...); err != nil {
+		t.Fatalf("publish: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second*5)
+	defer cancel()
+	if err := batcher.Flush(ctx); err != nil {
+		t.Fatalf("flush: %v", err)
+	}
+
+	sort.Strings(received)
+	if !reflect.DeepEqual(received, expect) {
+		t.Fatalf("redeliver: expect %v, got %v", expect, received)
+	}
+	if o := bucket.(*diskBucket); len(o.segments) != 1 || len(o.pending) != 0 || o.size != 0 {
+		t.Fatalf("reclaim: segments=%d, pending=%d, size=%d", len(o.segments), len(o.pending), o.size)
+	}
+}
@@ -0,0 +1,179 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-03-08
+
+package common
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+type (
+	// OverflowPolicy
+	// decide which item dropped when bucket is full.
+	OverflowPolicy string
+
+	// Dropper
+	// expose dropped counters of bucket, key formatted as policy/reason.
+	//
+	//   {
+	//     "drop-oldest/evicted": 10,
+	//     "block/timeout": 3
+	//   }
+	Dropper interface {
+		Dropped() map[string]int64
+	}
+
+	leveled interface {
+		Level() Level
+	}
+)
+
+const (
+	// OverflowBlock
+	// block producer until space released or timed out, new item dropped
+	// if timed out.
+	OverflowBlock OverflowPolicy = "block"
+
+	// OverflowDropByLevel
+	// evict DEBUG / INFO items before WARN, ERROR and FATAL. New item
+	// dropped if no item can be evicted.
+	OverflowDropByLevel OverflowPolicy = "drop-by-level"
+
+	// OverflowDropNewest
+	// drop new item, ErrBucketIsFull returned.
+	OverflowDropNewest OverflowPolicy = "drop-newest"
+
+	// OverflowDropOldest
+	// evict the oldest item to make room for new item.
+	OverflowDropOldest OverflowPolicy = "drop-oldest"
+)
+
+const (
+	dropReasonEvicted = "evicted"
+	dropReasonFull    = "full"
+	dropReasonTimeout = "timeout"
+)
+
+// Dropped
+// return a copy of dropped counters.
+func (o *bucket) Dropped() map[string]int64 {
+	o.Lock()
+	defer o.Unlock()
+
+	res := make(map[string]int64)
+	for k, v := range o.drops {
+		res[k] = v
+	}
+	return res
+}
+
+// /////////////////////////////////////////////////////////////////////////////
+// Access methods, called with lock held.
+// /////////////////////////////////////////////////////////////////////////////
+
+func (o *bucket) drop(policy OverflowPolicy, reason string) {
+	o.drops[fmt.Sprintf("%s/%s", policy, reason)]++
+}
+
+// evict
+// the oldest item of the least severe level, DEBUG first then INFO.
+func (o *bucket) evict(item interface{}) error {
+	var (
+		idx = -1
+		max = Warn.Int()
+	)
+
+	for i, v := range o.caches {
+		if n := o.level(v); n > max {
+			idx, max = i, n
+		}
+	}
+
+	// Drop new item
+	// if nothing evictable or new item is less severe.
+	if idx == -1 || o.level(item) > max {
+		o.drop(OverflowDropByLevel, dropReasonFull)
+		return ErrBucketIsFull
+	}
+
+	o.drop(OverflowDropByLevel, strings.ToLower(o.caches[idx].(leveled).Level().String()))
+	n := copy(o.caches[idx:], o.caches[idx+1:])
+	o.caches[idx+n] = nil
+	o.caches = o.caches[:idx+n]
+	return nil
+}
+
+func (o *bucket) level(item interface{}) int {
+	if v, ok := item.(leveled); ok {
+		return v.Level().Int()
+	}
+	return 0
+}
+
+func (o *bucket) notify() {
+	if o.waiter != nil {
+		close(o.waiter)
+		o.waiter = nil
+	}
+}
+
+func (o *bucket) overflow(item interface{}) error {
+	switch o.policy {
+	case OverflowBlock:
+		return o.wait()
+	case OverflowDropByLevel:
+		return o.evict(item)
+	case OverflowDropOldest:
+		// Release reference
+		// of evicted item before reslice.
+		o.drop(OverflowDropOldest, dropReasonEvicted)
+		o.caches[0] = nil
+		o.caches = o.caches[1:]
+		return nil
+	}
+
+	o.drop(OverflowDropNewest, dropReasonFull)
+	return ErrBucketIsFull
+}
+
+// wait
+// until items popped or timed out.
+func (o *bucket) wait() error {
+	deadline := time.Now().Add(o.timeout)
+
+	for o.capacity > 0 && len(o.caches) >= o.capacity {
+		d := time.Until(deadline)
+		if d <= 0 {
+			o.drop(OverflowBlock, dropReasonTimeout)
+			return ErrBucketIsFull
+		}
+
+		if o.waiter == nil {
+			o.waiter = make(chan bool)
+		}
+
+		ch, ti := o.waiter, time.NewTimer(d)
+		o.Unlock()
+		select {
+		case <-ch:
+		case <-ti.C:
+		}
+		ti.Stop()
+		o.Lock()
+	}
+	return nil
+}
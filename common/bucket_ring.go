@@ -0,0 +1,192 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-03-09
+
+package common
+
+import (
+	"fmt"
+	"sync/atomic"
+)
+
+type (
+	// BatchPopper
+	// pop items into caller owned slice, so the slice can be reused
+	// between batches.
+	BatchPopper interface {
+		PopnTo(dst []interface{}, limit int) (items []interface{}, total, count int)
+	}
+
+	// ringBucket
+	// bounded lock-free queue, multiple producers and consumers are
+	// allowed. Each cell carries a sequence number, producers and consumers
+	// claim cells by CAS on head / tail position.
+	ringBucket struct {
+		_    [8]uint64
+		head uint64
+		_    [7]uint64
+		tail uint64
+		_    [7]uint64
+
+		cells   []ringCell
+		dropped int64
+		mask    uint64
+	}
+
+	ringCell struct {
+		item interface{}
+		seq  uint64
+	}
+)
+
+// NewRingBucket
+// create and return lock-free Bucket component. Capacity is rounded up to
+// power of 2 and fixed after created, new item rejected when full.
+func NewRingBucket(capacity int) Bucket {
+	return (&ringBucket{}).init(capacity)
+}
+
+// /////////////////////////////////////////////////////////////////////////////
+// Interface methods
+// /////////////////////////////////////////////////////////////////////////////
+
+func (o *ringBucket) Add(item interface{}) (total int, err error) {
+	var (
+		cell *ringCell
+		pos  = atomic.LoadUint64(&o.head)
+	)
+
+	for {
+		cell = &o.cells[pos&o.mask]
+		seq := atomic.LoadUint64(&cell.seq)
+
+		if dif := int64(seq) - int64(pos); dif == 0 {
+			if atomic.CompareAndSwapUint64(&o.head, pos, pos+1) {
+				break
+			}
+		} else if dif < 0 {
+			atomic.AddInt64(&o.dropped, 1)
+			return o.Count(), ErrBucketIsFull
+		} else {
+			pos = atomic.LoadUint64(&o.head)
+		}
+	}
+
+	cell.item = item
+	atomic.StoreUint64(&cell.seq, pos+1)
+	return o.Count(), nil
+}
+
+func (o *ringBucket) Count() int {
+	tail := atomic.LoadUint64(&o.tail)
+	head := atomic.LoadUint64(&o.head)
+	if head > tail {
+		return int(head - tail)
+	}
+	return 0
+}
+
+// Dropped
+// return rejected counter.
+func (o *ringBucket) Dropped() map[string]int64 {
+	return map[string]int64{
+		fmt.Sprintf("%s/%s", OverflowDropNewest, dropReasonFull): atomic.LoadInt64(&o.dropped),
+	}
+}
+
+func (o *ringBucket) IsEmpty() bool {
+	return 0 == o.Count()
+}
+
+func (o *ringBucket) Pop() (item interface{}, exists bool) {
+	return o.pop()
+}
+
+func (o *ringBucket) Popn(limit int) (items []interface{}, total, count int) {
+	if n := o.Count(); n < limit {
+		limit = n
+	}
+	if limit <= 0 {
+		return
+	}
+	return o.PopnTo(make([]interface{}, 0, limit), limit)
+}
+
+// PopnTo
+// pop specified count items, append them to dst[:0].
+func (o *ringBucket) PopnTo(dst []interface{}, limit int) (items []interface{}, total, count int) {
+	items = dst[:0]
+	total = o.Count()
+
+	for count < limit {
+		item, exists := o.pop()
+		if !exists {
+			break
+		}
+		items = append(items, item)
+		count++
+	}
+	return
+}
+
+// SetCapacity
+// ignored, capacity of ring is fixed after created.
+func (o *ringBucket) SetCapacity(_ int) Bucket { return o }
+
+// /////////////////////////////////////////////////////////////////////////////
+// Access and constructor
+// /////////////////////////////////////////////////////////////////////////////
+
+func (o *ringBucket) init(capacity int) *ringBucket {
+	n := uint64(2)
+	for n < uint64(capacity) {
+		n <<= 1
+	}
+
+	o.cells = make([]ringCell, n)
+	o.mask = n - 1
+	for i := range o.cells {
+		o.cells[i].seq = uint64(i)
+	}
+	return o
+}
+
+func (o *ringBucket) pop() (item interface{}, exists bool) {
+	var (
+		cell *ringCell
+		pos  = atomic.LoadUint64(&o.tail)
+	)
+
+	for {
+		cell = &o.cells[pos&o.mask]
+		seq := atomic.LoadUint64(&cell.seq)
+
+		if dif := int64(seq) - int64(pos+1); dif == 0 {
+			if atomic.CompareAndSwapUint64(&o.tail, pos, pos+1) {
+				break
+			}
+		} else if dif < 0 {
+			return
+		} else {
+			pos = atomic.LoadUint64(&o.tail)
+		}
+	}
+
+	// Release reference
+	// then hand the cell back to producers.
+	item, exists = cell.item, true
+	cell.item = nil
+	atomic.StoreUint64(&cell.seq, pos+o.mask+1)
+	return
+}
@@ -0,0 +1,76 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-03-09
+
+package common
+
+import (
+	"testing"
+)
+
+func BenchmarkBucketRing(b *testing.B)  { benchmarkBucket(b, NewRingBucket(8192)) }
+func BenchmarkBucketSlice(b *testing.B) { benchmarkBucket(b, NewBucket(8192)) }
+
+// benchmarkBucket
+// add items by parallel producers, one consumer pop batches until
+// benchmark finished.
+func benchmarkBucket(b *testing.B, bucket Bucket) {
+	var (
+		done = make(chan bool)
+		stop = make(chan bool)
+	)
+
+	go func() {
+		defer close(done)
+		for {
+			select {
+			case <-stop:
+				return
+			default:
+				bucket.Popn(100)
+			}
+		}
+	}()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			_, _ = bucket.Add(1)
+		}
+	})
+	b.StopTimer()
+
+	close(stop)
+	<-done
+}
+
+// TestBucketDropOldest
+// evicted item is not referenced by backing array of bucket.
+func TestBucketDropOldest(t *testing.T) {
+	o := NewBucketWithPolicy(2, OverflowDropOldest, 0).(*bucket)
+	_, _ = o.Add("a")
+	_, _ = o.Add("b")
+
+	backing := o.caches[:cap(o.caches)]
+	if _, err := o.Add("c"); err != nil {
+		t.Fatalf("add: %v", err)
+	}
+	if backing[0] != nil {
+		t.Fatalf("evicted item referenced: %v", backing[0])
+	}
+	if items, _, _ := o.Popn(2); len(items) != 2 || items[0] != "b" || items[1] != "c" {
+		t.Fatalf("expected [b c], got %v", items)
+	}
+}
@@ -0,0 +1,246 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-03-06
+
+package common
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+type (
+	// FileFields
+	// placement of key/value pairs in json line, flat merged into record,
+	// nested under fields.
+	FileFields string
+
+	// FileFormat
+	// output format of file exporters.
+	FileFormat string
+
+	// SyncPolicy
+	// decide when buffered contents flushed and synced to disk.
+	SyncPolicy string
+
+	// FileOption
+	// expose file writer configuration methods. Implemented by
+	// configurer.FileLogger and configurer.FileTracer.
+	FileOption interface {
+		GetDirMode() os.FileMode
+		GetExt() string
+		GetFileMode() os.FileMode
+		GetFolder() string
+		GetName() string
+		GetPath() string
+		GetSync() SyncPolicy
+		GetSyncInterval() int
+	}
+
+	// FileWriter
+	// keep current file opened until time bucket changed, contents are
+	// written through a buffer.
+	FileWriter interface {
+		// Close
+		// flush buffer and close opened file. Next Write call will open
+		// it again.
+		Close() error
+
+		// Flush
+		// buffered contents into file.
+		Flush() error
+
+		// Write
+		// text into file which computed by time.
+		Write(t time.Time, text string) error
+	}
+
+	fileWriter struct {
+		sync.Mutex
+
+		buf      *bufio.Writer
+		fp       *os.File
+		instance string
+		name     string
+		option   func() FileOption
+		path     string
+		stop     chan bool
+	}
+)
+
+const (
+	FileFieldsFlat   FileFields = "flat"
+	FileFieldsNested FileFields = "nested"
+
+	FileFormatJson FileFormat = "json"
+	FileFormatText FileFormat = "text"
+)
+
+const (
+	SyncAlways   SyncPolicy = "always"
+	SyncInterval SyncPolicy = "interval"
+	SyncNever    SyncPolicy = "never"
+)
+
+// NewFileWriter
+// create and return FileWriter component. Option called on each open, so
+// changed configurations are applied at next time bucket. Files stored in
+// sub directory named by instance if not empty.
+func NewFileWriter(name, instance string, option func() FileOption) FileWriter {
+	return (&fileWriter{instance: instance, name: name, option: option}).init()
+}
+
+// /////////////////////////////////////////////////////////////////////////////
+// Interface methods
+// /////////////////////////////////////////////////////////////////////////////
+
+func (o *fileWriter) Close() error {
+	o.Lock()
+	defer o.Unlock()
+
+	return o.close()
+}
+
+func (o *fileWriter) Flush() error {
+	o.Lock()
+	defer o.Unlock()
+
+	return o.flush(o.option().GetSync() != SyncNever)
+}
+
+func (o *fileWriter) Write(t time.Time, text string) (err error) {
+	o.Lock()
+	defer o.Unlock()
+
+	var (
+		opt  = o.option()
+		dir  = filepath.Join(opt.GetPath(), o.instance, t.Format(opt.GetFolder()))
+		path = fmt.Sprintf("%s/%s.%s", dir, t.Format(opt.GetName()), opt.GetExt())
+	)
+
+	// Switch to next file
+	// if time bucket changed.
+	if path != o.path {
+		if err = o.close(); err != nil {
+			InternalInfo("<%s> close: %v", o.name, err)
+		}
+		if err = o.open(opt, dir, path); err != nil {
+			return
+		}
+	}
+
+	line := make([]byte, 0, len(text)+1)
+	line = append(append(line, text...), '\n')
+
+	// Flush buffered contents first if batch not fit, so a batch is
+	// written by one call. Batch larger than buffer written directly.
+	if len(line) > o.buf.Available() && o.buf.Buffered() > 0 {
+		if err = o.buf.Flush(); err != nil {
+			return
+		}
+	}
+	if _, err = o.buf.Write(line); err != nil {
+		return
+	}
+
+	// Flush and sync on each write, flush only
+	// if no periodic flush.
+	if opt.GetSync() == SyncAlways {
+		err = o.flush(true)
+	} else if o.stop == nil {
+		err = o.flush(false)
+	}
+	return
+}
+
+// /////////////////////////////////////////////////////////////////////////////
+// Access and constructor
+// /////////////////////////////////////////////////////////////////////////////
+
+func (o *fileWriter) close() (err error) {
+	if o.fp == nil {
+		return
+	}
+
+	if o.stop != nil {
+		close(o.stop)
+		o.stop = nil
+	}
+
+	if err = o.flush(o.option().GetSync() != SyncNever); err != nil {
+		_ = o.fp.Close()
+	} else {
+		err = o.fp.Close()
+	}
+
+	o.buf = nil
+	o.fp = nil
+	o.path = ""
+	return
+}
+
+func (o *fileWriter) flush(sync bool) (err error) {
+	if o.fp == nil {
+		return
+	}
+	if err = o.buf.Flush(); err == nil && sync {
+		err = o.fp.Sync()
+	}
+	return
+}
+
+func (o *fileWriter) init() *fileWriter {
+	return o
+}
+
+func (o *fileWriter) listen(stop chan bool, ms int) {
+	ti := time.NewTicker(time.Duration(ms) * time.Millisecond)
+	defer ti.Stop()
+
+	for {
+		select {
+		case <-ti.C:
+			if err := o.Flush(); err != nil {
+				InternalInfo("<%s> flush: %v", o.name, err)
+			}
+		case <-stop:
+			return
+		}
+	}
+}
+
+func (o *fileWriter) open(opt FileOption, dir, path string) (err error) {
+	if err = os.MkdirAll(dir, opt.GetDirMode()); err != nil {
+		return
+	}
+
+	if o.fp, err = os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, opt.GetFileMode()); err != nil {
+		return
+	}
+
+	o.buf = bufio.NewWriter(o.fp)
+	o.path = path
+
+	// Flush buffered contents
+	// periodically unless each write synced.
+	if ms := opt.GetSyncInterval(); opt.GetSync() != SyncAlways && ms > 0 {
+		o.stop = make(chan bool)
+		go o.listen(o.stop, ms)
+	}
+	return
+}
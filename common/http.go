@@ -0,0 +1,176 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-03-11
+
+package common
+
+import (
+	"context"
+	"fmt"
+	"github.com/valyala/fasthttp"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+type (
+	// Compression
+	// algorithm of request body compression.
+	Compression string
+
+	// HttpOption
+	// expose http exporter configuration methods. Implemented by
+	// configurer.JaegerTracer and configurer.ZipkinTracer.
+	HttpOption interface {
+		GetBearerToken() string
+		GetCompression() Compression
+		GetContentType() string
+		GetEndpoint() string
+		GetHeaders() map[string]string
+		GetTimeout() int
+	}
+)
+
+const (
+	CompressionGzip Compression = "gzip"
+	CompressionNone Compression = "none"
+)
+
+const (
+	httpBodyLimit = 256
+)
+
+// NewHttpClient
+// create and return http client for network exporters. TLS config applied
+// if enabled, error returned if tls config invalid. Endpoint is used to
+// verify host of server certificate.
+func NewHttpClient(name string, option TLSOption, endpoint func() string) (*fasthttp.Client, error) {
+	client := &fasthttp.Client{Name: name}
+
+	if option.GetEnable() {
+		cfg, err := NewTLSConfig(name, option, endpoint)
+		if err != nil {
+			return nil, fmt.Errorf("tls config: %v", err)
+		}
+		client.TLSConfig = cfg
+	}
+	return client, nil
+}
+
+// HttpPost
+// send body to endpoint of http option by client. Body is compressed once
+// before attempts, custom headers and bearer token are bound on each
+// attempt, build callback can override them. Retry stopped if context
+// cancelled.
+func HttpPost(ctx context.Context, client *fasthttp.Client, retry RetryOption, option HttpOption, body []byte, build func(req *fasthttp.Request)) error {
+	var encoding string
+
+	if option.GetCompression() == CompressionGzip {
+		body = fasthttp.AppendGzipBytes(nil, body)
+		encoding = string(CompressionGzip)
+	}
+
+	return httpSend(ctx, client, retry, time.Duration(option.GetTimeout())*time.Millisecond, func(req *fasthttp.Request) {
+		req.SetRequestURI(option.GetEndpoint())
+		req.SetBody(body)
+		req.Header.SetMethod(http.MethodPost)
+		req.Header.SetContentType(option.GetContentType())
+
+		if encoding != "" {
+			req.Header.Set(fasthttp.HeaderContentEncoding, encoding)
+		}
+		if token := option.GetBearerToken(); token != "" {
+			req.Header.Set(fasthttp.HeaderAuthorization, "Bearer "+token)
+		}
+		for k, v := range option.GetHeaders() {
+			req.Header.Set(k, v)
+		}
+
+		if build != nil {
+			build(req)
+		}
+	})
+}
+
+// httpSend
+// send request by client with timeout, fasthttp defaults used if client is
+// nil or timeout is zero. Network errors and response status 429 or 5xx are
+// retried, Retry-After header honored.
+func httpSend(ctx context.Context, client *fasthttp.Client, option RetryOption, timeout time.Duration, build func(req *fasthttp.Request)) error {
+	return Retry(ctx, option, func() error {
+		var (
+			req = fasthttp.AcquireRequest()
+			res = fasthttp.AcquireResponse()
+		)
+
+		defer func() {
+			fasthttp.ReleaseRequest(req)
+			fasthttp.ReleaseResponse(res)
+		}()
+
+		build(req)
+
+		if err := httpDo(client, req, res, timeout); err != nil {
+			return &RetryError{Err: err}
+		}
+		return httpStatus(res)
+	})
+}
+
+func httpDo(client *fasthttp.Client, req *fasthttp.Request, res *fasthttp.Response, timeout time.Duration) error {
+	if client == nil {
+		if timeout > 0 {
+			return fasthttp.DoTimeout(req, res, timeout)
+		}
+		return fasthttp.Do(req, res)
+	}
+	if timeout > 0 {
+		return client.DoTimeout(req, res, timeout)
+	}
+	return client.Do(req, res)
+}
+
+func httpStatus(res *fasthttp.Response) error {
+	code := res.StatusCode()
+	if code >= http.StatusOK && code < http.StatusMultipleChoices {
+		return nil
+	}
+
+	body := res.Body()
+	if len(body) > httpBodyLimit {
+		body = body[:httpBodyLimit]
+	}
+	err := fmt.Errorf("http status: code=%d, body=%s", code, body)
+
+	if code == http.StatusTooManyRequests || code >= http.StatusInternalServerError {
+		return &RetryError{After: httpRetryAfter(res), Err: err}
+	}
+	return err
+}
+
+// httpRetryAfter
+// parse Retry-After header, value is seconds or http date.
+func httpRetryAfter(res *fasthttp.Response) time.Duration {
+	s := string(res.Header.Peek("Retry-After"))
+	if s == "" {
+		return 0
+	}
+	if n, err := strconv.Atoi(s); err == nil && n > 0 {
+		return time.Duration(n) * time.Second
+	}
+	if t, err := http.ParseTime(s); err == nil {
+		return time.Until(t)
+	}
+	return 0
+}
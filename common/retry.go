@@ -0,0 +1,117 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-03-11
+
+package common
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand"
+	"time"
+)
+
+type (
+	// RetryOption
+	// expose retry policy configuration methods. Implemented by
+	// configurer.HttpRetry.
+	RetryOption interface {
+		// GetDeadline
+		// return max duration of all attempts, in milliseconds.
+		GetDeadline() int
+
+		// GetInitialBackoff
+		// return wait duration before second attempt, in milliseconds.
+		GetInitialBackoff() int
+
+		// GetJitter
+		// return random factor of backoff, between 0 and 1.
+		GetJitter() float64
+
+		// GetMaxAttempts
+		// return max attempts, first attempt included.
+		GetMaxAttempts() int
+
+		// GetMaxBackoff
+		// return max wait duration between attempts, in milliseconds.
+		GetMaxBackoff() int
+	}
+
+	// RetryError
+	// returned by retry callback if error can be retried. After is the
+	// duration suggested by server (eg. Retry-After header).
+	RetryError struct {
+		After time.Duration
+		Err   error
+	}
+)
+
+func (o *RetryError) Error() string { return o.Err.Error() }
+func (o *RetryError) Unwrap() error { return o.Err }
+
+// Retry
+// call function until succeed, non-retryable error returned, attempts
+// exhausted or context cancelled. Wait duration grows exponentially with
+// jitter.
+func Retry(ctx context.Context, option RetryOption, call func() error) (err error) {
+	var (
+		attempt  = 0
+		backoff  = time.Duration(option.GetInitialBackoff()) * time.Millisecond
+		deadline = time.Now().Add(time.Duration(option.GetDeadline()) * time.Millisecond)
+		max      = time.Duration(option.GetMaxBackoff()) * time.Millisecond
+	)
+
+	for {
+		attempt++
+
+		// Return
+		// if succeed or error can not be retried.
+		var re *RetryError
+		if err = call(); err == nil || !errors.As(err, &re) {
+			return
+		}
+
+		// Attempts exhausted.
+		if attempt >= option.GetMaxAttempts() {
+			return fmt.Errorf("retry attempts exhausted: attempts=%d, %w", attempt, re.Err)
+		}
+
+		// Compute wait duration.
+		wait := re.After
+		if wait <= 0 {
+			wait = backoff
+			if j := option.GetJitter(); j > 0 {
+				wait += time.Duration((rand.Float64()*2 - 1) * j * float64(wait))
+			}
+			if backoff *= 2; backoff > max {
+				backoff = max
+			}
+		}
+
+		// Give up
+		// if deadline reached.
+		if time.Now().Add(wait).After(deadline) {
+			return fmt.Errorf("retry deadline exceeded: attempts=%d, %w", attempt, re.Err)
+		}
+
+		// Give up
+		// if cancelled while waiting.
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("retry cancelled: attempts=%d, %v: %w", attempt, ctx.Err(), re.Err)
+		case <-time.After(wait):
+		}
+	}
+}
@@ -0,0 +1,62 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-03-11
+
+package common
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+type (
+	testRetryOption struct{}
+)
+
+func (testRetryOption) GetDeadline() int       { return 60000 }
+func (testRetryOption) GetInitialBackoff() int { return 30000 }
+func (testRetryOption) GetJitter() float64     { return 0 }
+func (testRetryOption) GetMaxAttempts() int    { return 3 }
+func (testRetryOption) GetMaxBackoff() int     { return 30000 }
+
+// TestRetryCancelled
+// waiting between attempts stopped once context cancelled, last error of
+// call is kept.
+func TestRetryCancelled(t *testing.T) {
+	var (
+		attempts int
+		cause    = errors.New("unavailable")
+	)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*50)
+	defer cancel()
+
+	begin := time.Now()
+	err := Retry(ctx, testRetryOption{}, func() error {
+		attempts++
+		return &RetryError{Err: cause}
+	})
+
+	if !errors.Is(err, cause) {
+		t.Fatalf("expected error wraps %v, got %v", cause, err)
+	}
+	if attempts != 1 {
+		t.Fatalf("expected 1 attempt, got %d", attempts)
+	}
+	if d := time.Since(begin); d > time.Second {
+		t.Fatalf("expected return once cancelled, took %v", d)
+	}
+}
@@ -11,26 +11,41 @@
 // limitations under the License.
 //
 // author: wsfuyibing <websearch@163.com>
-// date: 2023-03-04
+// date: 2023-03-03
 
 package common
 
 import (
-	"regexp"
-	"runtime/debug"
-	"strconv"
+	"reflect"
+	"runtime"
 	"strings"
+	"sync"
+)
+
+const (
+	// StackDepth
+	// max frames captured by Backstack and logs.
+	StackDepth = 32
 )
 
 var (
-	stackBegin           = "runtime/debug.Stack()"
-	stackRegexFile       = regexp.MustCompile(`^([^:]+):(\d+)`)
-	stackRegexFunc       = regexp.MustCompile(`([^/]+)$`)
-	stackRegexFuncParams = regexp.MustCompile(`\([0{][^)]+\)`)
-	stackRegexInternal   = regexp.MustCompile(`/fuyibing/log/v5[./]`)
+	// Module path,
+	// eg. github.com/fuyibing/log/v5.
+	stackModule = strings.TrimSuffix(reflect.TypeOf(StackItem{}).PkgPath(), "/common")
 )
 
 type (
+	// LazyStack
+	// program counters of goroutine, frames are resolved when Items called
+	// first time, so cost of symbolization paid only if printed.
+	LazyStack struct {
+		items []StackItem
+		once  sync.Once
+		pcs   []uintptr
+	}
+
+	// Stack
+	// resolved frames of goroutine, returned by Backstack.
 	Stack struct {
 		Items []StackItem
 	}
@@ -55,64 +70,67 @@ type (
 )
 
 // Backstack
-// extract debug stack into list.
-func Backstack() Stack {
-	var (
-		call       string
-		lines      = strings.Split(string(debug.Stack()), "\n")
-		stack      = Stack{Items: make([]StackItem, 0)}
-		startIndex int
-		startMax   = len(lines)
-	)
+// capture and resolve stack of caller, at most StackDepth frames. Use
+// NewLazyStack if frames may not be printed.
+func Backstack() Stack { return Stack{Items: NewLazyStack(1, StackDepth).Items()} }
+
+// NewLazyStack
+// capture at most limit frames, skip frames above caller of NewLazyStack.
+func NewLazyStack(skip, limit int) *LazyStack {
+	pcs := make([]uintptr, limit)
+	return &LazyStack{pcs: pcs[:runtime.Callers(skip+2, pcs)]}
+}
 
-	// Range stack
-	// to find start line number.
-	for i, s := range lines {
-		if s = strings.TrimSpace(s); s == "" {
-			break
-		}
-		if s == stackBegin {
-			startIndex = i + 2
-			break
-		}
-	}
+// NewLazyStackFromCallers
+// return stack of program counters returned by runtime.Callers.
+func NewLazyStackFromCallers(pcs []uintptr) *LazyStack { return &LazyStack{pcs: pcs} }
 
-	// Range stack
-	// from start number.
-	for i := startIndex; i < startMax; i += 2 {
-		if call = lines[i]; call == "" {
-			break
-		}
-
-		// Init item struct.
-		item := StackItem{
-			Call:     call,
-			Internal: stackRegexInternal.MatchString(call),
-		}
+// NewLazyStackFromItems
+// return stack which frames resolved already, eg. decoded from disk.
+func NewLazyStackFromItems(items []StackItem) *LazyStack {
+	o := &LazyStack{items: items}
+	o.once.Do(func() {})
+	return o
+}
 
-		// Remove func params.
-		item.Call = stackRegexFuncParams.ReplaceAllString(item.Call, `()`)
+// Items
+// return resolved frames.
+func (o *LazyStack) Items() []StackItem {
+	o.once.Do(o.resolve)
+	return o.items
+}
 
-		// Collect func name.
-		if m := stackRegexFunc.FindStringSubmatch(item.Call); len(m) > 0 {
-			item.Call = m[1]
-		}
+func (o *LazyStack) resolve() {
+	var (
+		frames = runtime.CallersFrames(o.pcs)
+		items  = make([]StackItem, 0, len(o.pcs))
+	)
 
-		// Parse file and line.
-		if (i + 1) < startMax {
-			if item.File = strings.TrimSpace(lines[i+1]); item.File != "" {
-				if m := stackRegexFile.FindStringSubmatch(item.File); len(m) > 0 {
-					item.File = m[1]
-					if n, ne := strconv.ParseInt(m[2], 10, 32); ne == nil {
-						item.Line = int(n)
-					}
-				}
+	for {
+		frame, more := frames.Next()
+		if frame.Function != "" {
+			var (
+				name = frame.Function[strings.LastIndex(frame.Function, "/")+1:]
+				pkg  = frame.Function[:len(frame.Function)-len(name)]
+			)
+
+			// Package path
+			// ended with first dot of short name.
+			if i := strings.Index(name, "."); i >= 0 {
+				pkg += name[:i]
 			}
-		}
 
-		// Append to list.
-		stack.Items = append(stack.Items, item)
+			items = append(items, StackItem{
+				Call:     name + "()",
+				File:     frame.File,
+				Internal: pkg == stackModule || strings.HasPrefix(pkg, stackModule+"/"),
+				Line:     frame.Line,
+			})
+		}
+		if !more {
+			break
+		}
 	}
 
-	return stack
+	o.items = items
 }
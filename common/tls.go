@@ -0,0 +1,248 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-03-12
+
+package common
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"sync"
+	"time"
+)
+
+type (
+	// TLSOption
+	// expose tls configuration methods. Implemented by configurer.TLS.
+	TLSOption interface {
+		GetCaFile() string
+		GetCertFile() string
+		GetEnable() bool
+		GetInsecureSkipVerify() bool
+		GetKeyFile() string
+		GetMinVersion() string
+		GetReloadInterval() int
+		GetServerName() string
+	}
+
+	tlsLoader struct {
+		sync.Mutex
+
+		cert     *tls.Certificate
+		checked  time.Time
+		endpoint func() string
+		mtimes   map[string]time.Time
+		name     string
+		option   TLSOption
+		pool     *x509.CertPool
+	}
+)
+
+var (
+	ErrTLSNoCertificate = errors.New("tls client certificate not configured")
+	ErrTLSNoServerName  = errors.New("tls server name not resolved")
+
+	tlsVersions = map[string]uint16{
+		"1.0": tls.VersionTLS10,
+		"1.1": tls.VersionTLS11,
+		"1.2": tls.VersionTLS12,
+		"1.3": tls.VersionTLS13,
+	}
+)
+
+// NewTLSConfig
+// create and return tls config for network exporters. CA file, client cert
+// and key are loaded on handshake and reloaded if files modified, so rotated
+// certificates applied without restart. Server certificate verified against
+// server name of option, host of endpoint used if not configured.
+func NewTLSConfig(name string, option TLSOption, endpoint func() string) (*tls.Config, error) {
+	ver, ok := tlsVersions[option.GetMinVersion()]
+	if !ok {
+		return nil, fmt.Errorf("unknown tls min version: %s", option.GetMinVersion())
+	}
+
+	o := &tlsLoader{endpoint: endpoint, name: name, option: option, mtimes: make(map[string]time.Time)}
+	if err := o.load(); err != nil {
+		return nil, err
+	}
+
+	cfg := &tls.Config{
+		MinVersion: ver,
+		ServerName: option.GetServerName(),
+	}
+
+	if option.GetCertFile() != "" {
+		cfg.GetClientCertificate = o.certificate
+	}
+
+	// Verify server certificate manually, so CA pool can be replaced at
+	// runtime. Builtin verification is disabled by InsecureSkipVerify.
+	cfg.InsecureSkipVerify = true
+	if !option.GetInsecureSkipVerify() {
+		cfg.VerifyConnection = o.verify
+	}
+	return cfg, nil
+}
+
+// /////////////////////////////////////////////////////////////////////////////
+// Access methods
+// /////////////////////////////////////////////////////////////////////////////
+
+func (o *tlsLoader) certificate(_ *tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	o.reload()
+
+	o.Lock()
+	defer o.Unlock()
+
+	if o.cert == nil {
+		return nil, ErrTLSNoCertificate
+	}
+	return o.cert, nil
+}
+
+// changed
+// return true if any file modified since last loaded.
+func (o *tlsLoader) changed() bool {
+	for _, file := range []string{o.option.GetCaFile(), o.option.GetCertFile(), o.option.GetKeyFile()} {
+		if file == "" {
+			continue
+		}
+		if info, err := os.Stat(file); err == nil && !info.ModTime().Equal(o.mtimes[file]) {
+			return true
+		}
+	}
+	return false
+}
+
+func (o *tlsLoader) load() (err error) {
+	var (
+		cert   *tls.Certificate
+		mtimes = make(map[string]time.Time)
+		pool   *x509.CertPool
+	)
+
+	for _, file := range []string{o.option.GetCaFile(), o.option.GetCertFile(), o.option.GetKeyFile()} {
+		if file == "" {
+			continue
+		}
+		var info os.FileInfo
+		if info, err = os.Stat(file); err != nil {
+			return
+		}
+		mtimes[file] = info.ModTime()
+	}
+
+	// Load CA pool,
+	// system pool used if not configured.
+	if file := o.option.GetCaFile(); file != "" {
+		var buf []byte
+		if buf, err = os.ReadFile(file); err != nil {
+			return
+		}
+		pool = x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(buf) {
+			return fmt.Errorf("no certificate found in ca file: %s", file)
+		}
+	} else if pool, err = x509.SystemCertPool(); err != nil {
+		return
+	}
+
+	// Load client certificate
+	// for mutual tls.
+	if o.option.GetCertFile() != "" {
+		var c tls.Certificate
+		if c, err = tls.LoadX509KeyPair(o.option.GetCertFile(), o.option.GetKeyFile()); err != nil {
+			return
+		}
+		cert = &c
+	}
+
+	o.Lock()
+	o.cert, o.mtimes, o.pool = cert, mtimes, pool
+	o.Unlock()
+	return
+}
+
+// reload
+// files if modified, check at most once per reload interval. Previous
+// certificates kept if reload failed.
+func (o *tlsLoader) reload() {
+	o.Lock()
+	if time.Since(o.checked) < time.Duration(o.option.GetReloadInterval())*time.Millisecond {
+		o.Unlock()
+		return
+	}
+	o.checked = time.Now()
+	changed := o.changed()
+	o.Unlock()
+
+	if !changed {
+		return
+	}
+
+	if err := o.load(); err != nil {
+		InternalInfo("<%s> tls reload: %v", o.name, err)
+		return
+	}
+	InternalInfo("<%s> tls reloaded", o.name)
+}
+
+func (o *tlsLoader) verify(cs tls.ConnectionState) error {
+	if len(cs.PeerCertificates) == 0 {
+		return errors.New("tls server certificate not presented")
+	}
+
+	o.reload()
+
+	o.Lock()
+	pool := o.pool
+	o.Unlock()
+
+	opts := x509.VerifyOptions{
+		DNSName:       o.serverName(cs),
+		Intermediates: x509.NewCertPool(),
+		Roots:         pool,
+	}
+
+	// Hostname verification
+	// skipped by x509 if name is empty.
+	if opts.DNSName == "" {
+		return ErrTLSNoServerName
+	}
+	for _, c := range cs.PeerCertificates[1:] {
+		opts.Intermediates.AddCert(c)
+	}
+
+	_, err := cs.PeerCertificates[0].Verify(opts)
+	return err
+}
+
+// serverName
+// return expected host of server certificate. Server name of option
+// preferred, then host of endpoint, then server name of handshake.
+func (o *tlsLoader) serverName(cs tls.ConnectionState) string {
+	if s := o.option.GetServerName(); s != "" {
+		return s
+	}
+	if o.endpoint != nil {
+		if u, err := url.Parse(o.endpoint()); err == nil && u.Hostname() != "" {
+			return u.Hostname()
+		}
+	}
+	return cs.ServerName
+}
@@ -0,0 +1,96 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-03-07
+
+package configurer
+
+type (
+	// ConfigBucketSpill
+	// expose disk spillover for bucket.
+	ConfigBucketSpill interface {
+		GetBucketSpill() BucketSpill
+	}
+
+	// BucketSpill
+	// expose bucket spillover configuration methods.
+	BucketSpill interface {
+		GetEnable() bool
+		GetMaxSize() int64
+		GetPath() string
+		GetSegmentSize() int64
+	}
+
+	bucketSpill struct {
+		// Spill to disk when bucket is full.
+		// Default: false
+		Enable bool `yaml:"enable"`
+
+		// Max disk size of all segments.
+		// Default: 268,435,456 (256 MB)
+		MaxSize int64 `yaml:"max-size"`
+
+		// Segment files location.
+		// Default: ./logs/spill
+		Path string `yaml:"path"`
+
+		// Segment file size.
+		// Default: 8,388,608 (8 MB)
+		SegmentSize int64 `yaml:"segment-size"`
+	}
+)
+
+// Getter
+
+func (o *config) GetBucketSpill() BucketSpill { return o.BucketSpill }
+
+func (o *bucketSpill) GetEnable() bool       { return o.Enable }
+func (o *bucketSpill) GetMaxSize() int64     { return o.MaxSize }
+func (o *bucketSpill) GetPath() string       { return o.Path }
+func (o *bucketSpill) GetSegmentSize() int64 { return o.SegmentSize }
+
+// Setter.
+
+func (o *Setter) SetBucketSpillEnable(b bool) *Setter {
+	o.config.BucketSpill.Enable = b
+	return o
+}
+
+func (o *Setter) SetBucketSpillMaxSize(n int64) *Setter {
+	o.config.BucketSpill.MaxSize = n
+	return o
+}
+
+func (o *Setter) SetBucketSpillPath(s string) *Setter {
+	o.config.BucketSpill.Path = s
+	return o
+}
+
+func (o *Setter) SetBucketSpillSegmentSize(n int64) *Setter {
+	o.config.BucketSpill.SegmentSize = n
+	return o
+}
+
+// Defaults
+
+func (o *bucketSpill) initDefaults() {
+	if o.MaxSize == 0 {
+		o.MaxSize = defaultBucketSpillMaxSize
+	}
+	if o.Path == "" {
+		o.Path = defaultBucketSpillPath
+	}
+	if o.SegmentSize == 0 {
+		o.SegmentSize = defaultBucketSpillSegmentSize
+	}
+}
@@ -0,0 +1,94 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-03-12
+
+package configurer
+
+type (
+	// ConfigCircuitBreaker
+	// expose circuit breaker for remote exporters (eg. Jaeger, Zipkin).
+	ConfigCircuitBreaker interface {
+		GetCircuitBreaker() CircuitBreaker
+	}
+
+	// CircuitBreaker
+	// expose circuit breaker configuration methods.
+	CircuitBreaker interface {
+		GetCooldown() int
+		GetEnable() bool
+		GetFailureThreshold() int
+		GetFallback() string
+	}
+
+	circuitBreaker struct {
+		// Stay open for 30,000 ms, then a trial batch sent.
+		// Default: 30000 (Millisecond)
+		Cooldown int `yaml:"cooldown"`
+
+		// Enable circuit breaker.
+		// Default: false
+		Enable bool `yaml:"enable"`
+
+		// Open after 5 consecutive failures.
+		// Default: 5
+		FailureThreshold int `yaml:"failure-threshold"`
+
+		// Exporter name which receive batch while breaker is open.
+		// Accept: file, term.
+		// Default: "" (dropped)
+		Fallback string `yaml:"fallback"`
+	}
+)
+
+// Getter
+
+func (o *config) GetCircuitBreaker() CircuitBreaker { return o.CircuitBreaker }
+
+func (o *circuitBreaker) GetCooldown() int         { return o.Cooldown }
+func (o *circuitBreaker) GetEnable() bool          { return o.Enable }
+func (o *circuitBreaker) GetFailureThreshold() int { return o.FailureThreshold }
+func (o *circuitBreaker) GetFallback() string      { return o.Fallback }
+
+// Setter.
+
+func (o *Setter) SetCircuitBreakerCooldown(n int) *Setter {
+	o.config.CircuitBreaker.Cooldown = n
+	return o
+}
+
+func (o *Setter) SetCircuitBreakerEnable(b bool) *Setter {
+	o.config.CircuitBreaker.Enable = b
+	return o
+}
+
+func (o *Setter) SetCircuitBreakerFailureThreshold(n int) *Setter {
+	o.config.CircuitBreaker.FailureThreshold = n
+	return o
+}
+
+func (o *Setter) SetCircuitBreakerFallback(s string) *Setter {
+	o.config.CircuitBreaker.Fallback = s
+	return o
+}
+
+// Defaults
+
+func (o *circuitBreaker) initDefaults() {
+	if o.Cooldown == 0 {
+		o.Cooldown = defaultCircuitBreakerCooldown
+	}
+	if o.FailureThreshold == 0 {
+		o.FailureThreshold = defaultCircuitBreakerFailureThreshold
+	}
+}
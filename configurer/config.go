@@ -19,7 +19,11 @@ import (
 	"github.com/fuyibing/log/v5/common"
 	"gopkg.in/yaml.v3"
 	"os"
+	"reflect"
+	"strconv"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
 var (
@@ -31,12 +35,14 @@ type (
 	// expose basic configuration methods.
 	Configuration interface {
 		ConfigBucket
+		ConfigBucketSpill
 		ConfigOpenTracing
 
 		// For Logger.
 
 		ConfigLogger
 		ConfigLoggerFile
+		ConfigLoggerSampling
 
 		// For Tracer.
 
@@ -45,9 +51,35 @@ type (
 		ConfigTracerZipkin
 		ConfigTracerFile
 
+		// For http exporters.
+
+		ConfigCircuitBreaker
+		ConfigHttpRetry
+		ConfigTLS
+
+		// Sensitive data.
+
+		ConfigRedact
+
+		// Reload and validation.
+
+		ConfigHotReload
+		ConfigReload
+		ConfigValidate
+
 		// Set able.
 
 		Setter() *Setter
+
+		// Clone
+		// return an independent copy of all fields, changes of copy by
+		// Setter or reload do not affect origin.
+		Clone() Configuration
+
+		// GetInstance
+		// return instance name, files and spilled segments are stored in
+		// sub directory named by instance if not empty.
+		GetInstance() string
 	}
 
 	// Setter
@@ -61,6 +93,14 @@ type (
 		OpenTracingSpanId  string `yaml:"open-tracing-span-id"`
 		OpenTracingTraceId string `yaml:"open-tracing-trace-id"`
 
+		// Instance name, sub directory of files and spilled segments.
+		// Default: empty for global manager, instance-N for log.New
+		Instance string `yaml:"instance"`
+
+		// Refuse to start manager if configuration invalid.
+		// Default: false
+		Strict bool `yaml:"strict"`
+
 		// +-------------------------------------------------------------------+
 		// | Bucket for ASYNC                                                  |
 		// +-------------------------------------------------------------------+
@@ -81,6 +121,28 @@ type (
 		// Default: 200 (Millisecond)
 		BucketFrequency int `yaml:"bucket-frequency"`
 
+		// Bucket implementation.
+		// Accept: slice, ring.
+		// Default: slice
+		BucketType common.BucketType `yaml:"bucket-type"`
+
+		// Max encoded bytes of a batch sent by network exporters, batch
+		// split if exceeded.
+		// Default: 4,194,304 (4 MiB)
+		MaxBatchBytes int `yaml:"max-batch-bytes"`
+
+		// Spill to disk when bucket is full.
+		BucketSpill *bucketSpill `yaml:"bucket-spill"`
+
+		// Overflow policy when bucket is full.
+		// Accept: block, drop-oldest, drop-newest, drop-by-level.
+		// Default: drop-newest
+		OverflowPolicy common.OverflowPolicy `yaml:"overflow-policy"`
+
+		// Max block time for block policy.
+		// Default: 100 (Millisecond)
+		OverflowTimeout int `yaml:"overflow-timeout"`
+
 		// +-------------------------------------------------------------------+
 		// | Logger                                                            |
 		// +-------------------------------------------------------------------+
@@ -89,6 +151,28 @@ type (
 		// Default: INFO.
 		LoggerLevel common.Level `yaml:"logger-level"`
 
+		// Level of named logger or package prefix, eg. orders: DEBUG,
+		// longest prefix matched, global level used if not matched.
+		LoggerLevels map[string]common.Level `yaml:"logger-levels"`
+
+		// Resolve package of caller by runtime.Caller, used to match
+		// logger-levels if logger not named.
+		// Default: false
+		LoggerLevelsCaller bool `yaml:"logger-levels-caller"`
+
+		// Capture file, line and function of log statement.
+		// Default: false
+		LoggerCaller bool `yaml:"logger-caller"`
+
+		// Frames skipped after first caller outside this module, used by
+		// wrappers of this package.
+		// Default: 0
+		LoggerCallerSkip int `yaml:"logger-caller-skip"`
+
+		// Capture stack for levels, OFF disable all.
+		// Default: [FATAL]
+		StackLevels []common.Level `yaml:"stack-levels"`
+
 		// Logger name.
 		// Accept: term, file.
 		// Default: term
@@ -97,6 +181,9 @@ type (
 		// Save custom log to local files.
 		FileLogger *fileLogger `yaml:"file-logger"`
 
+		// Limit duplicate logs.
+		LoggerSampling *loggerSampling `yaml:"logger-sampling"`
+
 		// +-------------------------------------------------------------------+
 		// | Tracer                                                            |
 		// +-------------------------------------------------------------------+
@@ -119,45 +206,135 @@ type (
 		// Upload span to Zipkin.
 		ZipkinTracer *zipkinTracer `yaml:"zipkin-tracer"`
 
+		// Retry policy for Jaeger and Zipkin.
+		HttpRetry *httpRetry `yaml:"http-retry"`
+
+		// Circuit breaker for Jaeger and Zipkin.
+		CircuitBreaker *circuitBreaker `yaml:"circuit-breaker"`
+
+		// TLS for network exporters.
+		TLS *tlsConfig `yaml:"tls"`
+
+		// Reload config file without restart.
+		HotReload *hotReload `yaml:"hot-reload"`
+
+		// Redact sensitive data of logs and spans.
+		Redact *redact `yaml:"redact"`
+
 		// +-------------------------------------------------------------------+
 		// | Internal                                                          |
 		// +-------------------------------------------------------------------+
 
-		setter                                    *Setter
-		debugOn, infoOn, warnOn, errorOn, fatalOn bool
+		issues []error
+		live   atomic.Value
+		mtime  time.Time
+		mu     sync.Mutex
+		path   string
+		setter *Setter
+		source map[string]interface{}
 	}
 )
 
-func (o *config) Setter() *Setter { return o.setter }
+func (o *config) GetInstance() string { return o.Instance }
+func (o *config) Setter() *Setter     { return o.setter }
+
+func (o *config) Clone() Configuration {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	// Copy leaf fields,
+	// maps and slices are not shared.
+	next := (&config{}).defaults()
+	curr := make(map[string]reflect.Value)
+	fields(reflect.ValueOf(next).Elem(), "", curr)
+	for key, v := range o.snapshot() {
+		curr[key].Set(duplicate(reflect.ValueOf(v)))
+	}
+
+	next.issues = append([]error(nil), o.issues...)
+	next.mtime, next.path = o.mtime, o.path
+	next.source = make(map[string]interface{}, len(o.source))
+	for key, v := range o.source {
+		next.source[key] = duplicate(reflect.ValueOf(v)).Interface()
+	}
+	next.state()
+	return next
+}
+
+func (o *Setter) SetInstance(s string) *Setter { o.config.Instance = s; return o }
 
 // /////////////////////////////////////////////////////////////////////////////
 // Access and constructor
 // /////////////////////////////////////////////////////////////////////////////
 
+// load
+// read specified config file then assign to configuration fields.
+func (o *config) load(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	buf, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+	if err = yaml.Unmarshal(buf, o); err != nil {
+		return err
+	}
+	o.unknown(path, buf)
+	o.mtime, o.path = info.ModTime(), path
+	return nil
+}
+
+// scan
+// config file specified by LOG_CONFIG environment variable, otherwise
+// config/log.yaml and ../config/log.yaml relative to working directory.
 func (o *config) scan() {
-	// Read config file then assign to configuration fields.
+	if path := os.Getenv(EnvConfig); path != "" {
+		o.path = path
+		if err := o.load(path); err != nil {
+			common.InternalInfo("<configurer> load %s: %v", path, err)
+			o.issue("load %s: %v", path, err)
+		}
+		return
+	}
+
 	for _, path := range []string{"config/log.yaml", "../config/log.yaml"} {
-		if buf, err := os.ReadFile(path); err == nil {
-			if yaml.Unmarshal(buf, o) == nil {
-				return
-			}
+		if err := o.load(path); err == nil {
+			return
+		} else if !os.IsNotExist(err) {
+			common.InternalInfo("<configurer> load %s: %v", path, err)
+			o.issue("load %s: %v", path, err)
+			return
 		}
 	}
 }
 
 func (o *config) init() *config {
 	o.scan()
+	if err := o.env(); err != nil {
+		common.InternalInfo("<configurer> %v", err)
+		o.issue("%v", err)
+	}
+	return o.defaults()
+}
+
+// defaults
+// apply default values to fields which not specified.
+func (o *config) defaults() *config {
 	o.setter = &Setter{config: o}
 
 	// Init default fields.
 
 	o.defaultBucket()
+	o.initBucketSpill()
 	o.defaultOpenTracing()
 
 	// Logger definitions.
 
 	o.defaultLogger()
 	o.initFileLogger()
+	o.initLoggerSampling()
 
 	// Tracer{file|jaeger|zipkin}
 
@@ -165,9 +342,33 @@ func (o *config) init() *config {
 	o.initFileTracer()
 	o.initJaegerTracer()
 	o.initZipkinTracer()
+	o.initHttpRetry()
+	o.initCircuitBreaker()
+	o.initTLS()
+	o.initHotReload()
+	o.initRedact()
+
+	// Values loaded,
+	// compared on reload.
+	o.source = o.snapshot()
+	o.state()
 	return o
 }
 
+func (o *config) initBucketSpill() {
+	if o.BucketSpill == nil {
+		o.BucketSpill = &bucketSpill{}
+	}
+	o.BucketSpill.initDefaults()
+}
+
+func (o *config) initCircuitBreaker() {
+	if o.CircuitBreaker == nil {
+		o.CircuitBreaker = &circuitBreaker{}
+	}
+	o.CircuitBreaker.initDefaults()
+}
+
 func (o *config) initFileLogger() {
 	if o.FileLogger == nil {
 		o.FileLogger = &fileLogger{}
@@ -182,6 +383,20 @@ func (o *config) initFileTracer() {
 	o.FileTracer.initDefaults()
 }
 
+func (o *config) initHotReload() {
+	if o.HotReload == nil {
+		o.HotReload = &hotReload{}
+	}
+	o.HotReload.initDefaults()
+}
+
+func (o *config) initHttpRetry() {
+	if o.HttpRetry == nil {
+		o.HttpRetry = &httpRetry{}
+	}
+	o.HttpRetry.initDefaults()
+}
+
 func (o *config) initJaegerTracer() {
 	if o.JaegerTracer == nil {
 		o.JaegerTracer = &jaegerTracer{}
@@ -189,6 +404,28 @@ func (o *config) initJaegerTracer() {
 	o.JaegerTracer.initDefaults()
 }
 
+func (o *config) initLoggerSampling() {
+	if o.LoggerSampling == nil {
+		o.LoggerSampling = &loggerSampling{}
+	}
+	o.LoggerSampling.initDefaults()
+}
+
+func (o *config) initRedact() {
+	if o.Redact == nil {
+		o.Redact = &redact{}
+	}
+	o.Redact.initDefaults()
+	o.Redact.compile()
+}
+
+func (o *config) initTLS() {
+	if o.TLS == nil {
+		o.TLS = &tlsConfig{}
+	}
+	o.TLS.initDefaults()
+}
+
 func (o *config) initZipkinTracer() {
 	if o.ZipkinTracer == nil {
 		o.ZipkinTracer = &zipkinTracer{}
@@ -196,4 +433,36 @@ func (o *config) initZipkinTracer() {
 	o.ZipkinTracer.initDefaults()
 }
 
-func init() { new(sync.Once).Do(func() { Config = (&config{}).init() }) }
+// fileMode
+// parse octal permission string, return def if not specified or invalid.
+func fileMode(s string, def os.FileMode) os.FileMode {
+	if s != "" {
+		if n, err := strconv.ParseUint(s, 8, 32); err == nil {
+			return os.FileMode(n)
+		}
+	}
+	return def
+}
+
+// Load
+// create and return an independent configuration from specified file.
+// Precedence from low to high: defaults, config file, LOG_ environment
+// variables, Setter.
+func Load(path string) (Configuration, error) {
+	o := &config{}
+	if err := o.load(path); err != nil {
+		return nil, err
+	}
+	if err := o.env(); err != nil {
+		return nil, err
+	}
+	return o.defaults(), nil
+}
+
+// New
+// create and return an independent configuration, fields are read from
+// config file then LOG_ environment variables and defaults applied. Changes
+// by Setter don't affect global Config.
+func New() Configuration { return (&config{}).init() }
+
+func init() { new(sync.Once).Do(func() { Config = New() }) }
@@ -15,6 +15,10 @@
 
 package configurer
 
+import (
+	"github.com/fuyibing/log/v5/common"
+)
+
 type (
 	// ConfigBucket
 	// expose bucket (memory queue) configuration methods.
@@ -23,22 +27,56 @@ type (
 		GetBucketCapacity() int
 		GetBucketConcurrency() int32
 		GetBucketFrequency() int
+		GetBucketType() common.BucketType
+		GetMaxBatchBytes() int
+		GetOverflowPolicy() common.OverflowPolicy
+		GetOverflowTimeout() int
 	}
 )
 
 // Getter
 
-func (o *config) GetBucketBatch() int         { return o.BucketBatch }
-func (o *config) GetBucketCapacity() int      { return o.BucketCapacity }
-func (o *config) GetBucketConcurrency() int32 { return o.BucketConcurrency }
-func (o *config) GetBucketFrequency() int     { return o.BucketFrequency }
+func (o *config) GetBucketBatch() int                      { return o.current().bucketBatch }
+func (o *config) GetBucketCapacity() int                   { return o.BucketCapacity }
+func (o *config) GetBucketConcurrency() int32              { return o.current().bucketConcurrency }
+func (o *config) GetBucketFrequency() int                  { return o.current().bucketFrequency }
+func (o *config) GetBucketType() common.BucketType         { return o.BucketType }
+func (o *config) GetMaxBatchBytes() int                    { return o.MaxBatchBytes }
+func (o *config) GetOverflowPolicy() common.OverflowPolicy { return o.OverflowPolicy }
+func (o *config) GetOverflowTimeout() int                  { return o.OverflowTimeout }
 
 // Setter
 
-func (o *Setter) SetBucketBatch(n int) *Setter         { o.config.BucketBatch = n; return o }
-func (o *Setter) SetBucketCapacity(n int) *Setter      { o.config.BucketCapacity = n; return o }
-func (o *Setter) SetBucketConcurrency(n int32) *Setter { o.config.BucketConcurrency = n; return o }
-func (o *Setter) SetBucketFrequency(n int) *Setter     { o.config.BucketFrequency = n; return o }
+func (o *Setter) SetBucketCapacity(n int) *Setter           { o.config.BucketCapacity = n; return o }
+func (o *Setter) SetBucketType(v common.BucketType) *Setter { o.config.BucketType = v; return o }
+func (o *Setter) SetMaxBatchBytes(n int) *Setter            { o.config.MaxBatchBytes = n; return o }
+func (o *Setter) SetOverflowTimeout(n int) *Setter          { o.config.OverflowTimeout = n; return o }
+
+func (o *Setter) SetBucketBatch(n int) *Setter {
+	o.config.update(func() {
+		o.config.BucketBatch = n
+	})
+	return o
+}
+
+func (o *Setter) SetBucketConcurrency(n int32) *Setter {
+	o.config.update(func() {
+		o.config.BucketConcurrency = n
+	})
+	return o
+}
+
+func (o *Setter) SetBucketFrequency(n int) *Setter {
+	o.config.update(func() {
+		o.config.BucketFrequency = n
+	})
+	return o
+}
+
+func (o *Setter) SetOverflowPolicy(v common.OverflowPolicy) *Setter {
+	o.config.OverflowPolicy = v
+	return o
+}
 
 // Access
 
@@ -55,4 +93,16 @@ func (o *config) defaultBucket() {
 	if o.BucketFrequency == 0 {
 		o.BucketFrequency = defaultBucketFrequency
 	}
+	if o.BucketType == "" {
+		o.BucketType = defaultBucketType
+	}
+	if o.MaxBatchBytes == 0 {
+		o.MaxBatchBytes = defaultMaxBatchBytes
+	}
+	if o.OverflowPolicy == "" {
+		o.OverflowPolicy = defaultOverflowPolicy
+	}
+	if o.OverflowTimeout == 0 {
+		o.OverflowTimeout = defaultOverflowTimeout
+	}
 }
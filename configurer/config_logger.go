@@ -17,46 +17,160 @@ package configurer
 
 import (
 	"github.com/fuyibing/log/v5/common"
+	"strings"
+	"sync"
 )
 
 type (
 	// ConfigLogger
 	// expose logger configuration methods.
 	ConfigLogger interface {
+		GetLoggerCaller() bool
+		GetLoggerCallerSkip() int
 		GetLoggerExporter() string
 		GetLoggerLevel() common.Level
+		GetLoggerLevels() map[string]common.Level
+
+		// GetLoggerLevelRules
+		// return global level and levels of named loggers, read from same
+		// published values.
+		GetLoggerLevelRules() (common.Level, map[string]common.Level)
+
+		GetLoggerLevelsCaller() bool
 		LevelEnabled(level common.Level) bool
+
+		// LevelEnabledFor
+		// return true if level enabled for named logger or package path,
+		// resolved by longest prefix of logger-levels, global level used if
+		// not matched.
+		LevelEnabledFor(name string, level common.Level) bool
+
+		GetStackLevels() []common.Level
+
+		// StackEnabled
+		// return true if stack captured for level.
+		StackEnabled(level common.Level) bool
+	}
+
+	// levelTable
+	// prefix rules of logger-levels, resolved level of each name cached.
+	levelTable struct {
+		cache sync.Map
+		rules map[string]int
 	}
 )
 
 // Getter
 
-func (o *config) LevelEnabled(level common.Level) (yes bool) {
-	switch level {
-	case common.Debug:
-		yes = o.debugOn
-	case common.Info:
-		yes = o.infoOn
-	case common.Warn:
-		yes = o.warnOn
-	case common.Error:
-		yes = o.errorOn
-	case common.Fatal:
-		yes = o.fatalOn
+func (o *config) LevelEnabled(level common.Level) bool {
+	n := level.Int()
+	return n > common.Off.Int() && o.current().level >= n
+}
+
+func (o *config) LevelEnabledFor(name string, level common.Level) bool {
+	c := o.current()
+	if name != "" && len(c.levels.rules) > 0 {
+		if li := c.levels.resolve(name); li > 0 {
+			n := level.Int()
+			return n > common.Off.Int() && li >= n
+		}
 	}
-	return
+	n := level.Int()
+	return n > common.Off.Int() && c.level >= n
 }
 
-func (o *config) GetLoggerExporter() string    { return o.LoggerExporter }
-func (o *config) GetLoggerLevel() common.Level { return o.LoggerLevel }
+func (o *config) StackEnabled(level common.Level) bool {
+	return o.current().stackMask&(1<<level.Int()) != 0
+}
+
+func (o *config) GetLoggerLevelRules() (common.Level, map[string]common.Level) {
+	c := o.current()
+	return c.loggerLevel, c.loggerLevels
+}
+
+func (o *config) GetLoggerCaller() bool                    { return o.current().loggerCaller }
+func (o *config) GetLoggerCallerSkip() int                 { return o.current().loggerCallerSkip }
+func (o *config) GetLoggerExporter() string                { return o.current().loggerExporter }
+func (o *config) GetLoggerLevel() common.Level             { return o.current().loggerLevel }
+func (o *config) GetLoggerLevels() map[string]common.Level { return o.current().loggerLevels }
+func (o *config) GetLoggerLevelsCaller() bool              { return o.current().loggerLevelsCaller }
+func (o *config) GetStackLevels() []common.Level           { return o.current().stackLevels }
 
 // Setter
 
-func (o *Setter) SetLoggerExporter(v string) *Setter { o.config.LoggerExporter = v; return o }
+func (o *Setter) SetLoggerCaller(b bool) *Setter {
+	o.config.update(func() {
+		o.config.LoggerCaller = b
+	})
+	return o
+}
+
+func (o *Setter) SetLoggerCallerSkip(n int) *Setter {
+	o.config.update(func() {
+		o.config.LoggerCallerSkip = n
+	})
+	return o
+}
+
+func (o *Setter) SetLoggerExporter(v string) *Setter {
+	o.config.update(func() {
+		o.config.LoggerExporter = v
+	})
+	return o
+}
 
 func (o *Setter) SetLoggerLevel(v common.Level) *Setter {
-	o.config.LoggerLevel = v
-	o.config.state()
+	o.config.update(func() {
+		o.config.LoggerLevel = v
+	})
+	return o
+}
+
+// SetLoggerLevelFor
+// set level of named logger or package prefix, rule removed if level is
+// empty.
+func (o *Setter) SetLoggerLevelFor(name string, v common.Level) *Setter {
+	o.config.update(func() {
+		m := make(map[string]common.Level, len(o.config.LoggerLevels)+1)
+		for k, l := range o.config.LoggerLevels {
+			m[k] = l
+		}
+		if v == "" {
+			delete(m, name)
+		} else {
+			m[name] = v.Upper()
+		}
+		o.config.LoggerLevels = m
+	})
+	return o
+}
+
+func (o *Setter) SetLoggerLevels(v map[string]common.Level) *Setter {
+	o.config.update(func() {
+		m := make(map[string]common.Level, len(v))
+		for k, l := range v {
+			m[k] = l.Upper()
+		}
+		o.config.LoggerLevels = m
+	})
+	return o
+}
+
+func (o *Setter) SetLoggerLevelsCaller(b bool) *Setter {
+	o.config.update(func() {
+		o.config.LoggerLevelsCaller = b
+	})
+	return o
+}
+
+func (o *Setter) SetStackLevels(v ...common.Level) *Setter {
+	o.config.update(func() {
+		list := make([]common.Level, 0, len(v))
+		for _, l := range v {
+			list = append(list, l.Upper())
+		}
+		o.config.StackLevels = list
+	})
 	return o
 }
 
@@ -67,22 +181,45 @@ func (o *config) defaultLogger() {
 		o.LoggerExporter = defaultLoggerExporter
 	}
 
+	for k, l := range o.LoggerLevels {
+		o.LoggerLevels[k] = l.Upper()
+	}
+
+	if len(o.StackLevels) == 0 {
+		o.StackLevels = []common.Level{defaultStackLevel}
+	}
+	for i, l := range o.StackLevels {
+		o.StackLevels[i] = l.Upper()
+	}
+
 	if o.LoggerLevel.Upper().Int() == 0 {
+		if o.LoggerLevel != "" {
+			o.issue("logger-level: unknown level %q, %s used", o.LoggerLevel, defaultLoggerLevel)
+		}
 		o.LoggerLevel = defaultLoggerLevel
 	} else {
 		o.LoggerLevel = o.LoggerLevel.Upper()
 	}
-
-	o.state()
 }
 
-func (o *config) state() {
-	li := o.LoggerLevel.Int()
-	yes := li > common.Off.Int()
+// resolve
+// return level integer of longest rule which equal to name or prefix of
+// name separated by slash, zero returned if not matched.
+func (t *levelTable) resolve(name string) int {
+	if v, ok := t.cache.Load(name); ok {
+		return v.(int)
+	}
+
+	var (
+		li  int
+		max = -1
+	)
+	for k, n := range t.rules {
+		if len(k) > max && (name == k || strings.HasPrefix(name, k+"/")) {
+			li, max = n, len(k)
+		}
+	}
 
-	o.debugOn = yes && li >= common.Debug.Int()
-	o.errorOn = yes && li >= common.Error.Int()
-	o.fatalOn = yes && li >= common.Fatal.Int()
-	o.infoOn = yes && li >= common.Info.Int()
-	o.warnOn = yes && li >= common.Warn.Int()
+	t.cache.Store(name, li)
+	return li
 }
@@ -27,15 +27,32 @@ type (
 
 // Getter
 
-func (o *config) GetOpenTracingSampled() string { return o.OpenTracingSampled }
-func (o *config) GetOpenTracingSpanId() string  { return o.OpenTracingSpanId }
-func (o *config) GetOpenTracingTraceId() string { return o.OpenTracingTraceId }
+func (o *config) GetOpenTracingSampled() string { return o.current().openTracingSampled }
+func (o *config) GetOpenTracingSpanId() string  { return o.current().openTracingSpanId }
+func (o *config) GetOpenTracingTraceId() string { return o.current().openTracingTraceId }
 
 // Setter
 
-func (o *Setter) SetOpenTracingSampled(s string) *Setter { o.config.OpenTracingSampled = s; return o }
-func (o *Setter) SetOpenTracingSpanId(s string) *Setter  { o.config.OpenTracingSpanId = s; return o }
-func (o *Setter) SetOpenTracingTraceId(s string) *Setter { o.config.OpenTracingTraceId = s; return o }
+func (o *Setter) SetOpenTracingSampled(s string) *Setter {
+	o.config.update(func() {
+		o.config.OpenTracingSampled = s
+	})
+	return o
+}
+
+func (o *Setter) SetOpenTracingSpanId(s string) *Setter {
+	o.config.update(func() {
+		o.config.OpenTracingSpanId = s
+	})
+	return o
+}
+
+func (o *Setter) SetOpenTracingTraceId(s string) *Setter {
+	o.config.update(func() {
+		o.config.OpenTracingTraceId = s
+	})
+	return o
+}
 
 // Access
 
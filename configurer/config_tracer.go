@@ -26,13 +26,19 @@ type (
 
 // Getter
 
-func (o *config) GetTracerExporter() string { return o.TracerExporter }
+func (o *config) GetTracerExporter() string { return o.current().tracerExporter }
 func (o *config) GetTracerTopic() string    { return o.TracerTopic }
 
 // Setter
 
-func (o *Setter) SetTracerExporter(s string) *Setter { o.config.TracerExporter = s; return o }
-func (o *Setter) SetTracerTopic(s string) *Setter    { o.config.TracerTopic = s; return o }
+func (o *Setter) SetTracerTopic(s string) *Setter { o.config.TracerTopic = s; return o }
+
+func (o *Setter) SetTracerExporter(s string) *Setter {
+	o.config.update(func() {
+		o.config.TracerExporter = s
+	})
+	return o
+}
 
 // Access
 
@@ -24,15 +24,31 @@ const (
 	defaultBucketCapacity    = 30000
 	defaultBucketConcurrency = 10
 	defaultBucketFrequency   = 200
+	defaultBucketType        = common.BucketSlice
+	defaultMaxBatchBytes     = 4 * 1024 * 1024
+
+	defaultBucketSpillMaxSize     = 256 * 1024 * 1024
+	defaultBucketSpillPath        = "./logs/spill"
+	defaultBucketSpillSegmentSize = 8 * 1024 * 1024
+
+	defaultOverflowPolicy  = common.OverflowDropNewest
+	defaultOverflowTimeout = 100
 )
 
 const (
 	defaultLoggerLevel    = common.Info
 	defaultLoggerExporter = "term"
+	defaultStackLevel     = common.Fatal
 	defaultTracerTopic    = "log-trace"
 	defaultTracerExporter = "term"
 )
 
+const (
+	defaultLoggerSamplingInitial    = 100
+	defaultLoggerSamplingInterval   = 1000
+	defaultLoggerSamplingThereafter = 100
+)
+
 const (
 	defaultOpenTracingSampled = "X-B3-Sampled"
 	defaultOpenTracingSpanId  = "X-B3-Spanid"
@@ -41,14 +57,57 @@ const (
 
 const (
 	defaultFileLoggerExt    = "log"
+	defaultFileLoggerFields = common.FileFieldsNested
+	defaultFileLoggerFormat = common.FileFormatText
 	defaultFileLoggerFolder = "2006-01"
 	defaultFileLoggerName   = "2006-01-02"
 	defaultFileLoggerPath   = "./logs"
+
+	defaultFileLoggerDirMode      = "0755"
+	defaultFileLoggerFileMode     = "0644"
+	defaultFileLoggerSync         = common.SyncInterval
+	defaultFileLoggerSyncInterval = 1000
 )
 
 const (
 	defaultFileTracerExt    = "trace"
+	defaultFileTracerFields = common.FileFieldsNested
+	defaultFileTracerFormat = common.FileFormatText
 	defaultFileTracerFolder = "2006-01"
 	defaultFileTracerName   = "2006-01-02"
 	defaultFileTracerPath   = "./logs"
+
+	defaultFileTracerDirMode      = "0755"
+	defaultFileTracerFileMode     = "0644"
+	defaultFileTracerSync         = common.SyncInterval
+	defaultFileTracerSyncInterval = 1000
+)
+
+const (
+	defaultHttpRetryDeadline       = 30000
+	defaultHttpRetryInitialBackoff = 100
+	defaultHttpRetryJitter         = 0.2
+	defaultHttpRetryMaxAttempts    = 3
+	defaultHttpRetryMaxBackoff     = 5000
+)
+
+const (
+	defaultCircuitBreakerCooldown         = 30000
+	defaultCircuitBreakerFailureThreshold = 5
+)
+
+const (
+	defaultJaegerTracerCompression = common.CompressionNone
+	defaultJaegerTracerTimeout     = 5000
+	defaultZipkinTracerCompression = common.CompressionNone
+	defaultZipkinTracerTimeout     = 5000
+)
+
+const (
+	defaultTLSMinVersion     = "1.2"
+	defaultTLSReloadInterval = 10000
+)
+
+const (
+	defaultHotReloadInterval = 3000
 )
@@ -0,0 +1,153 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-03-16
+
+package configurer
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+const (
+	// EnvConfig
+	// environment variable name of config file path.
+	EnvConfig = "LOG_CONFIG"
+
+	// EnvPrefix
+	// prefix of environment variables which override config fields.
+	EnvPrefix = "LOG_"
+)
+
+// env
+// override fields by environment variables. Name built by yaml keys of
+// field path, joined by underscore and converted to upper case.
+//
+//   tracer-exporter           -> LOG_TRACER_EXPORTER
+//   jaeger-tracer.endpoint    -> LOG_JAEGER_TRACER_ENDPOINT
+//   zipkin-tracer.headers     -> LOG_ZIPKIN_TRACER_HEADERS="k1=v1,k2=v2"
+func (o *config) env() error {
+	return envStruct(reflect.ValueOf(o).Elem(), strings.TrimSuffix(EnvPrefix, "_"))
+}
+
+func envStruct(rv reflect.Value, prefix string) error {
+	rt := rv.Type()
+
+	for i := 0; i < rt.NumField(); i++ {
+		sf := rt.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+
+		key := strings.Split(sf.Tag.Get("yaml"), ",")[0]
+		if key == "" || key == "-" {
+			continue
+		}
+
+		var (
+			fv   = rv.Field(i)
+			name = prefix + "_" + strings.ToUpper(strings.ReplaceAll(key, "-", "_"))
+		)
+
+		// Nested block,
+		// allocated if nil, defaults applied later.
+		if fv.Kind() == reflect.Ptr && fv.Type().Elem().Kind() == reflect.Struct {
+			if fv.IsNil() {
+				fv.Set(reflect.New(fv.Type().Elem()))
+			}
+			if err := envStruct(fv.Elem(), name); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if s, ok := os.LookupEnv(name); ok {
+			if err := envValue(fv, s); err != nil {
+				return fmt.Errorf("invalid environment variable %s=%q: %v", name, s, err)
+			}
+		}
+	}
+	return nil
+}
+
+func envValue(fv reflect.Value, s string) error {
+	switch fv.Kind() {
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		fv.SetBool(b)
+
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetFloat(f)
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetInt(n)
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, fv.Type().Bits())
+		if err != nil {
+			return err
+		}
+		fv.SetUint(n)
+
+	case reflect.String:
+		fv.SetString(s)
+
+	case reflect.Slice:
+		if fv.Type().Elem().Kind() != reflect.String {
+			return fmt.Errorf("unsupported type: %s", fv.Type())
+		}
+		list := reflect.MakeSlice(fv.Type(), 0, 0)
+		for _, item := range strings.Split(s, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				list = reflect.Append(list, reflect.ValueOf(item).Convert(fv.Type().Elem()))
+			}
+		}
+		fv.Set(list)
+
+	case reflect.Map:
+		if fv.Type().Key().Kind() != reflect.String || fv.Type().Elem().Kind() != reflect.String {
+			return fmt.Errorf("unsupported type: %s", fv.Type())
+		}
+		m := reflect.MakeMap(fv.Type())
+		for _, pair := range strings.Split(s, ",") {
+			if pair = strings.TrimSpace(pair); pair == "" {
+				continue
+			}
+			kv := strings.SplitN(pair, "=", 2)
+			if len(kv) != 2 {
+				return fmt.Errorf("key=value pair required: %s", pair)
+			}
+			m.SetMapIndex(reflect.ValueOf(strings.TrimSpace(kv[0])), reflect.ValueOf(strings.TrimSpace(kv[1])).Convert(fv.Type().Elem()))
+		}
+		fv.Set(m)
+
+	default:
+		return fmt.Errorf("unsupported type: %s", fv.Type())
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-03-18
+
+package configurer
+
+type (
+	// ConfigHotReload
+	// expose hot reload of config file.
+	ConfigHotReload interface {
+		GetHotReload() HotReload
+	}
+
+	// HotReload
+	// expose hot reload configuration methods.
+	HotReload interface {
+		GetEnable() bool
+		GetInterval() int
+		GetSignal() bool
+	}
+
+	hotReload struct {
+		// Watch config file and reload if modified.
+		// Default: false
+		Enable bool `yaml:"enable"`
+
+		// Check file modification every 3,000 ms.
+		// Default: 3000 (Millisecond)
+		Interval int `yaml:"interval"`
+
+		// Reload when SIGHUP received.
+		// Default: false
+		Signal bool `yaml:"signal"`
+	}
+)
+
+// Getter
+
+func (o *config) GetHotReload() HotReload { return o.HotReload }
+
+func (o *hotReload) GetEnable() bool  { return o.Enable }
+func (o *hotReload) GetInterval() int { return o.Interval }
+func (o *hotReload) GetSignal() bool  { return o.Signal }
+
+// Setter.
+
+func (o *Setter) SetHotReloadEnable(b bool) *Setter {
+	o.config.HotReload.Enable = b
+	return o
+}
+
+func (o *Setter) SetHotReloadInterval(n int) *Setter {
+	o.config.HotReload.Interval = n
+	return o
+}
+
+func (o *Setter) SetHotReloadSignal(b bool) *Setter {
+	o.config.HotReload.Signal = b
+	return o
+}
+
+// Defaults
+
+func (o *hotReload) initDefaults() {
+	if o.Interval == 0 {
+		o.Interval = defaultHotReloadInterval
+	}
+}
@@ -0,0 +1,125 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-03-11
+
+package configurer
+
+type (
+	// ConfigHttpRetry
+	// expose retry policy for http exporters (eg. Jaeger, Zipkin).
+	ConfigHttpRetry interface {
+		GetHttpRetry() HttpRetry
+	}
+
+	// HttpRetry
+	// expose http retry configuration methods.
+	HttpRetry interface {
+		GetDeadLetter() string
+		GetDeadline() int
+		GetInitialBackoff() int
+		GetJitter() float64
+		GetMaxAttempts() int
+		GetMaxBackoff() int
+	}
+
+	httpRetry struct {
+		// Exporter name which receive batch when retry given up.
+		// Accept: file, term.
+		// Default: "" (dropped)
+		DeadLetter string `yaml:"dead-letter"`
+
+		// Give up after 30,000 ms.
+		// Default: 30000 (Millisecond)
+		Deadline int `yaml:"deadline"`
+
+		// Wait 100 ms before second attempt, doubled on each attempt.
+		// Default: 100 (Millisecond)
+		InitialBackoff int `yaml:"initial-backoff"`
+
+		// Random factor of backoff.
+		// Default: 0.2
+		Jitter float64 `yaml:"jitter"`
+
+		// Max attempts, first attempt included.
+		// Default: 3
+		MaxAttempts int `yaml:"max-attempts"`
+
+		// Max wait duration between attempts.
+		// Default: 5000 (Millisecond)
+		MaxBackoff int `yaml:"max-backoff"`
+	}
+)
+
+// Getter
+
+func (o *config) GetHttpRetry() HttpRetry { return o.HttpRetry }
+
+func (o *httpRetry) GetDeadLetter() string  { return o.DeadLetter }
+func (o *httpRetry) GetDeadline() int       { return o.Deadline }
+func (o *httpRetry) GetInitialBackoff() int { return o.InitialBackoff }
+func (o *httpRetry) GetJitter() float64     { return o.Jitter }
+func (o *httpRetry) GetMaxAttempts() int    { return o.MaxAttempts }
+func (o *httpRetry) GetMaxBackoff() int     { return o.MaxBackoff }
+
+// Setter.
+
+func (o *Setter) SetHttpRetryDeadLetter(s string) *Setter {
+	o.config.HttpRetry.DeadLetter = s
+	return o
+}
+
+func (o *Setter) SetHttpRetryDeadline(n int) *Setter {
+	o.config.HttpRetry.Deadline = n
+	return o
+}
+
+func (o *Setter) SetHttpRetryInitialBackoff(n int) *Setter {
+	o.config.HttpRetry.InitialBackoff = n
+	return o
+}
+
+func (o *Setter) SetHttpRetryJitter(f float64) *Setter {
+	o.config.HttpRetry.Jitter = f
+	return o
+}
+
+func (o *Setter) SetHttpRetryMaxAttempts(n int) *Setter {
+	o.config.HttpRetry.MaxAttempts = n
+	return o
+}
+
+func (o *Setter) SetHttpRetryMaxBackoff(n int) *Setter {
+	o.config.HttpRetry.MaxBackoff = n
+	return o
+}
+
+// Defaults
+
+func (o *httpRetry) initDefaults() {
+	if o.Deadline == 0 {
+		o.Deadline = defaultHttpRetryDeadline
+	}
+	if o.InitialBackoff == 0 {
+		o.InitialBackoff = defaultHttpRetryInitialBackoff
+	}
+	if o.Jitter == 0 {
+		o.Jitter = defaultHttpRetryJitter
+	}
+	if o.MaxAttempts == 0 {
+		o.MaxAttempts = defaultHttpRetryMaxAttempts
+	}
+	if o.MaxBackoff == 0 {
+		o.MaxBackoff = defaultHttpRetryMaxBackoff
+	}
+}
@@ -15,6 +15,11 @@
 
 package configurer
 
+import (
+	"github.com/fuyibing/log/v5/common"
+	"os"
+)
+
 type (
 	// ConfigLoggerFile
 	// expose file adapter for logger.
@@ -25,10 +30,24 @@ type (
 	// FileLogger
 	// expose file logger configuration methods.
 	FileLogger interface {
+		GetDirMode() os.FileMode
 		GetExt() string
+
+		// GetFields
+		// return placement of key/value pairs in json format.
+		GetFields() common.FileFields
+
+		GetFileMode() os.FileMode
 		GetFolder() string
+
+		// GetFormat
+		// return output format, text or json lines.
+		GetFormat() common.FileFormat
+
 		GetName() string
 		GetPath() string
+		GetSync() common.SyncPolicy
+		GetSyncInterval() int
 	}
 
 	fileLogger struct {
@@ -36,43 +55,129 @@ type (
 		Folder string `yaml:"folder"`
 		Name   string `yaml:"name"`
 		Path   string `yaml:"path"`
+
+		// Directory and file permissions, octal string.
+		// Default: 0755, 0644
+		DirMode  string `yaml:"dir-mode"`
+		FileMode string `yaml:"file-mode"`
+
+		// Output format, json writes one object per line.
+		// Accept: text, json.
+		// Default: text
+		Format common.FileFormat `yaml:"format"`
+
+		// Key/value pairs of json format, flat merged into record.
+		// Accept: nested, flat.
+		// Default: nested
+		Fields common.FileFields `yaml:"fields"`
+
+		// Sync policy.
+		// Accept: always, interval, never.
+		// Default: interval
+		Sync common.SyncPolicy `yaml:"sync"`
+
+		// Flush buffer per 1000 ms if sync policy is not always.
+		// Default: 1000 (Millisecond)
+		SyncInterval int `yaml:"sync-interval"`
 	}
 )
 
 // Getter
 
-func (o *config) GetFileLogger() FileLogger { return o.FileLogger }
+func (o *config) GetFileLogger() FileLogger { return o.current().fileLogger }
 
-func (o *fileLogger) GetExt() string    { return o.Ext }
-func (o *fileLogger) GetFolder() string { return o.Folder }
-func (o *fileLogger) GetName() string   { return o.Name }
-func (o *fileLogger) GetPath() string   { return o.Path }
+func (o *fileLogger) GetDirMode() os.FileMode      { return fileMode(o.DirMode, 0755) }
+func (o *fileLogger) GetExt() string               { return o.Ext }
+func (o *fileLogger) GetFields() common.FileFields { return o.Fields }
+func (o *fileLogger) GetFileMode() os.FileMode     { return fileMode(o.FileMode, 0644) }
+func (o *fileLogger) GetFolder() string            { return o.Folder }
+func (o *fileLogger) GetFormat() common.FileFormat { return o.Format }
+func (o *fileLogger) GetName() string              { return o.Name }
+func (o *fileLogger) GetPath() string              { return o.Path }
+func (o *fileLogger) GetSync() common.SyncPolicy   { return o.Sync }
+func (o *fileLogger) GetSyncInterval() int         { return o.SyncInterval }
 
 // Setter.
 
+func (o *Setter) SetFileLoggerDirMode(s string) *Setter {
+	o.config.update(func() {
+		o.config.FileLogger.DirMode = s
+	})
+	return o
+}
+
 func (o *Setter) SetFileLoggerExt(s string) *Setter {
-	o.config.FileLogger.Ext = s
+	o.config.update(func() {
+		o.config.FileLogger.Ext = s
+	})
+	return o
+}
+
+func (o *Setter) SetFileLoggerFields(v common.FileFields) *Setter {
+	o.config.update(func() {
+		o.config.FileLogger.Fields = v
+	})
+	return o
+}
+
+func (o *Setter) SetFileLoggerFileMode(s string) *Setter {
+	o.config.update(func() {
+		o.config.FileLogger.FileMode = s
+	})
 	return o
 }
 
 func (o *Setter) SetFileLoggerFolder(s string) *Setter {
-	o.config.FileLogger.Folder = s
+	o.config.update(func() {
+		o.config.FileLogger.Folder = s
+	})
+	return o
+}
+
+func (o *Setter) SetFileLoggerFormat(v common.FileFormat) *Setter {
+	o.config.update(func() {
+		o.config.FileLogger.Format = v
+	})
 	return o
 }
 
 func (o *Setter) SetFileLoggerName(s string) *Setter {
-	o.config.FileLogger.Name = s
+	o.config.update(func() {
+		o.config.FileLogger.Name = s
+	})
 	return o
 }
 
 func (o *Setter) SetFileLoggerPath(s string) *Setter {
-	o.config.FileLogger.Path = s
+	o.config.update(func() {
+		o.config.FileLogger.Path = s
+	})
+	return o
+}
+
+func (o *Setter) SetFileLoggerSync(v common.SyncPolicy) *Setter {
+	o.config.update(func() {
+		o.config.FileLogger.Sync = v
+	})
+	return o
+}
+
+func (o *Setter) SetFileLoggerSyncInterval(n int) *Setter {
+	o.config.update(func() {
+		o.config.FileLogger.SyncInterval = n
+	})
 	return o
 }
 
 // Defaults
 
 func (o *fileLogger) initDefaults() {
+	if o.Fields == "" {
+		o.Fields = defaultFileLoggerFields
+	}
+	if o.Format == "" {
+		o.Format = defaultFileLoggerFormat
+	}
 	if o.Ext == "" {
 		o.Ext = defaultFileLoggerExt
 	}
@@ -85,4 +190,16 @@ func (o *fileLogger) initDefaults() {
 	if o.Path == "" {
 		o.Path = defaultFileLoggerPath
 	}
+	if o.DirMode == "" {
+		o.DirMode = defaultFileLoggerDirMode
+	}
+	if o.FileMode == "" {
+		o.FileMode = defaultFileLoggerFileMode
+	}
+	if o.Sync == "" {
+		o.Sync = defaultFileLoggerSync
+	}
+	if o.SyncInterval <= 0 {
+		o.SyncInterval = defaultFileLoggerSyncInterval
+	}
 }
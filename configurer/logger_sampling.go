@@ -0,0 +1,120 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-03-23
+
+package configurer
+
+type (
+	// ConfigLoggerSampling
+	// expose rate limiting of duplicate logs.
+	ConfigLoggerSampling interface {
+		GetLoggerSampling() LoggerSampling
+	}
+
+	// LoggerSampling
+	// expose logger sampling configuration methods.
+	LoggerSampling interface {
+		GetEnable() bool
+		GetInitial() int
+		GetInterval() int
+		GetSpan() bool
+		GetThereafter() int
+	}
+
+	loggerSampling struct {
+		// Limit logs of same level and format.
+		// Default: false
+		Enable bool `yaml:"enable"`
+
+		// First logs passed in each interval.
+		// Default: 100
+		Initial int `yaml:"initial"`
+
+		// Interval of counting.
+		// Default: 1000 (Millisecond)
+		Interval int `yaml:"interval"`
+
+		// Apply to span logs.
+		// Default: false
+		Span bool `yaml:"span"`
+
+		// Pass 1 of every thereafter logs after initial.
+		// Default: 100
+		Thereafter int `yaml:"thereafter"`
+	}
+)
+
+// Getter
+
+func (o *config) GetLoggerSampling() LoggerSampling { return o.current().sampling }
+
+func (o *loggerSampling) GetEnable() bool    { return o.Enable }
+func (o *loggerSampling) GetInitial() int    { return o.Initial }
+func (o *loggerSampling) GetInterval() int   { return o.Interval }
+func (o *loggerSampling) GetSpan() bool      { return o.Span }
+func (o *loggerSampling) GetThereafter() int { return o.Thereafter }
+
+// Setter.
+
+func (o *Setter) SetLoggerSamplingEnable(b bool) *Setter {
+	o.config.update(func() {
+		o.config.LoggerSampling.Enable = b
+	})
+	return o
+}
+
+func (o *Setter) SetLoggerSamplingInitial(n int) *Setter {
+	o.config.update(func() {
+		o.config.LoggerSampling.Initial = n
+		o.config.LoggerSampling.initDefaults()
+	})
+	return o
+}
+
+func (o *Setter) SetLoggerSamplingInterval(n int) *Setter {
+	o.config.update(func() {
+		o.config.LoggerSampling.Interval = n
+		o.config.LoggerSampling.initDefaults()
+	})
+	return o
+}
+
+func (o *Setter) SetLoggerSamplingSpan(b bool) *Setter {
+	o.config.update(func() {
+		o.config.LoggerSampling.Span = b
+	})
+	return o
+}
+
+func (o *Setter) SetLoggerSamplingThereafter(n int) *Setter {
+	o.config.update(func() {
+		o.config.LoggerSampling.Thereafter = n
+		o.config.LoggerSampling.initDefaults()
+	})
+	return o
+}
+
+// Defaults
+
+func (o *loggerSampling) initDefaults() {
+	if o.Initial <= 0 {
+		o.Initial = defaultLoggerSamplingInitial
+	}
+	if o.Interval <= 0 {
+		o.Interval = defaultLoggerSamplingInterval
+	}
+	if o.Thereafter <= 0 {
+		o.Thereafter = defaultLoggerSamplingThereafter
+	}
+}
@@ -0,0 +1,336 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-03-22
+
+package configurer
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"path"
+	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+const (
+	RedactDrop = "drop"
+	RedactHash = "hash"
+	RedactMask = "mask"
+
+	redactMasked = "***"
+)
+
+var (
+	// Builtin value rules,
+	// selected by name in redact.rules.
+	redactRules = map[string]redactRule{
+		"card":  {regexp.MustCompile(`\b\d(?:[ -]?\d){12,18}\b`), luhn},
+		"email": {regexp.MustCompile(`[A-Za-z0-9._%+-]+@[A-Za-z0-9.-]+\.[A-Za-z]{2,}`), nil},
+		"token": {regexp.MustCompile(`(?i)\bbearer\s+[A-Za-z0-9._~+/-]+=*|\beyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`), nil},
+	}
+
+	// Keys denied
+	// if redact.keys not specified.
+	defaultRedactKeys = []string{
+		"authorization",
+		"cookie",
+		"proxy-authorization",
+		"set-cookie",
+		"*password*",
+		"*secret*",
+	}
+
+	// Random key of process
+	// if redact.hash-key not specified.
+	redactHashKey     []byte
+	redactHashKeyOnce sync.Once
+)
+
+type (
+	// ConfigRedact
+	// expose redaction of sensitive data.
+	ConfigRedact interface {
+		GetRedact() Redact
+	}
+
+	// Redact
+	// expose redaction configuration methods.
+	Redact interface {
+		// Enabled
+		// return true if enabled and any rule configured.
+		Enabled() bool
+
+		GetEnable() bool
+		GetHashKey() string
+		GetKeys() []string
+		GetMode() string
+		GetPatterns() []string
+		GetRules() []string
+
+		// IsDrop
+		// return true if matched keys removed, mode compared
+		// case-insensitively.
+		IsDrop() bool
+
+		// MatchKey
+		// return true if key matched deny-list, case-insensitive.
+		MatchKey(key string) bool
+
+		// Replace
+		// apply value rules on text, return true if any matched.
+		Replace(text string) (string, bool)
+
+		// Value
+		// return redacted value by mode, empty for drop mode.
+		Value(s string) string
+	}
+
+	redact struct {
+		// Redact log and span fields.
+		// Default: false
+		Enable bool `yaml:"enable"`
+
+		// Secret of HMAC-SHA256 used by hash mode, random key generated
+		// per process if not specified.
+		HashKey string `yaml:"hash-key"`
+
+		// Denied keys, glob pattern accepted, eg. *password*.
+		// Default: [authorization, cookie, proxy-authorization, set-cookie, *password*, *secret*]
+		Keys []string `yaml:"keys"`
+
+		// Replacement of sensitive data.
+		// Accept: mask, hash, drop.
+		// Default: mask
+		Mode string `yaml:"mode"`
+
+		// Custom regular expressions of sensitive value.
+		Patterns []string `yaml:"patterns"`
+
+		// Builtin value rules.
+		// Accept: card, email, token.
+		Rules []string `yaml:"rules"`
+
+		compiled atomic.Value
+	}
+
+	redactCompiled struct {
+		hashKey  []byte
+		keys     []string
+		mode     string
+		patterns []redactRule
+	}
+
+	// redactRule
+	// regular expression of sensitive value, matched text redacted if
+	// valid is nil or returns true.
+	redactRule struct {
+		re    *regexp.Regexp
+		valid func(s string) bool
+	}
+)
+
+// Getter
+
+func (o *config) GetRedact() Redact { return o.current().redact }
+
+func (o *redact) GetEnable() bool       { return o.Enable }
+func (o *redact) GetHashKey() string    { return o.HashKey }
+func (o *redact) GetKeys() []string     { return o.Keys }
+func (o *redact) GetMode() string       { return o.Mode }
+func (o *redact) GetPatterns() []string { return o.Patterns }
+func (o *redact) GetRules() []string    { return o.Rules }
+
+func (o *redact) Enabled() bool {
+	c, ok := o.compiled.Load().(*redactCompiled)
+	return ok && c != nil
+}
+
+func (o *redact) IsDrop() bool {
+	c, ok := o.compiled.Load().(*redactCompiled)
+	return ok && c != nil && c.mode == RedactDrop
+}
+
+func (o *redact) MatchKey(key string) bool {
+	c, ok := o.compiled.Load().(*redactCompiled)
+	if !ok || c == nil {
+		return false
+	}
+
+	key = strings.ToLower(key)
+	for _, p := range c.keys {
+		if matched, _ := path.Match(p, key); matched {
+			return true
+		}
+	}
+	return false
+}
+
+func (o *redact) Replace(text string) (string, bool) {
+	c, ok := o.compiled.Load().(*redactCompiled)
+	if !ok || c == nil {
+		return text, false
+	}
+
+	found := false
+	for _, r := range c.patterns {
+		text = r.re.ReplaceAllStringFunc(text, func(s string) string {
+			if r.valid != nil && !r.valid(s) {
+				return s
+			}
+			found = true
+			return c.value(s)
+		})
+	}
+	return text, found
+}
+
+func (o *redact) Value(s string) string {
+	if c, ok := o.compiled.Load().(*redactCompiled); ok && c != nil {
+		return c.value(s)
+	}
+	return s
+}
+
+// Setter
+
+func (o *Setter) SetRedactEnable(b bool) *Setter {
+	o.config.update(func() {
+		o.config.Redact.Enable = b
+	})
+	return o
+}
+
+func (o *Setter) SetRedactHashKey(s string) *Setter {
+	o.config.update(func() {
+		o.config.Redact.HashKey = s
+	})
+	return o
+}
+
+func (o *Setter) SetRedactKeys(keys ...string) *Setter {
+	o.config.update(func() {
+		o.config.Redact.Keys = keys
+	})
+	return o
+}
+
+func (o *Setter) SetRedactMode(s string) *Setter {
+	o.config.update(func() {
+		o.config.Redact.Mode = s
+	})
+	return o
+}
+
+func (o *Setter) SetRedactPatterns(patterns ...string) *Setter {
+	o.config.update(func() {
+		o.config.Redact.Patterns = patterns
+	})
+	return o
+}
+
+func (o *Setter) SetRedactRules(rules ...string) *Setter {
+	o.config.update(func() {
+		o.config.Redact.Rules = rules
+	})
+	return o
+}
+
+// Defaults
+
+func (o *redact) initDefaults() {
+	if o.Keys == nil {
+		o.Keys = append([]string{}, defaultRedactKeys...)
+	}
+	if o.Mode == "" {
+		o.Mode = RedactMask
+	}
+}
+
+// compile
+// build key patterns and value rules, invalid patterns are ignored and
+// reported by Validate.
+func (o *redact) compile() {
+	if !o.Enable {
+		o.compiled.Store((*redactCompiled)(nil))
+		return
+	}
+
+	c := &redactCompiled{hashKey: []byte(o.HashKey), mode: strings.ToLower(o.Mode)}
+	if c.mode == RedactHash && len(c.hashKey) == 0 {
+		redactHashKeyOnce.Do(func() {
+			redactHashKey = make([]byte, 32)
+			_, _ = rand.Read(redactHashKey)
+		})
+		c.hashKey = redactHashKey
+	}
+	for _, k := range o.Keys {
+		if k = strings.ToLower(strings.TrimSpace(k)); k != "" {
+			c.keys = append(c.keys, k)
+		}
+	}
+	for _, name := range o.Rules {
+		if r, ok := redactRules[strings.ToLower(name)]; ok {
+			c.patterns = append(c.patterns, r)
+		}
+	}
+	for _, p := range o.Patterns {
+		if re, err := regexp.Compile(p); err == nil {
+			c.patterns = append(c.patterns, redactRule{re: re})
+		}
+	}
+
+	if len(c.keys) == 0 && len(c.patterns) == 0 {
+		c = nil
+	}
+	o.compiled.Store(c)
+}
+
+// value
+// return replacement of sensitive value.
+func (o *redactCompiled) value(s string) string {
+	switch o.mode {
+	case RedactDrop:
+		return ""
+	case RedactHash:
+		h := hmac.New(sha256.New, o.hashKey)
+		h.Write([]byte(s))
+		return "hmac:" + hex.EncodeToString(h.Sum(nil)[:8])
+	}
+	return redactMasked
+}
+
+// luhn
+// return true if digits of card number passed checksum, separators
+// ignored.
+func luhn(s string) bool {
+	var sum, n int
+	for i := len(s) - 1; i >= 0; i-- {
+		d := int(s[i] - '0')
+		if d < 0 || d > 9 {
+			continue
+		}
+		if n%2 == 1 {
+			if d *= 2; d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		n++
+	}
+	return n > 0 && sum%10 == 0
+}
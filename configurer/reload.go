@@ -0,0 +1,385 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-03-18
+
+package configurer
+
+import (
+	"errors"
+	"github.com/fuyibing/log/v5/common"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+type (
+	// ConfigReload
+	// expose reload methods of config file.
+	ConfigReload interface {
+		// LoadFrom
+		// read specified config file and environment variables, then apply
+		// all fields and watch the file by hot reload. Used to load global
+		// Config before manager started, fields changed by Setter are
+		// overridden.
+		LoadFrom(path string) error
+
+		// Modified
+		// return true if config file modified since loaded.
+		Modified() bool
+
+		// Reload
+		// read config file and environment variables again, then apply
+		// changed fields which safe at runtime. Fields changed by Setter are
+		// kept unless changed in config file too.
+		Reload() ([]Change, error)
+	}
+
+	// liveValues
+	// values read by getters of reloadable keys. Built by state and
+	// published as a whole, never modified after published, so readers
+	// see either old or new values of all keys.
+	liveValues struct {
+		bucketBatch        int
+		bucketConcurrency  int32
+		bucketFrequency    int
+		fileLogger         *fileLogger
+		fileTracer         *fileTracer
+		jaeger             *jaegerTracer
+		level              int
+		levels             *levelTable
+		loggerCaller       bool
+		loggerCallerSkip   int
+		loggerExporter     string
+		loggerLevel        common.Level
+		loggerLevels       map[string]common.Level
+		loggerLevelsCaller bool
+		openTracingSampled string
+		openTracingSpanId  string
+		openTracingTraceId string
+		redact             *redact
+		sampling           *loggerSampling
+		stackLevels        []common.Level
+		stackMask          int32
+		tracerExporter     string
+		zipkin             *zipkinTracer
+	}
+
+	// Change
+	// describe a field changed in config file. Applied is false if field
+	// takes effect after restart.
+	Change struct {
+		Applied  bool
+		Key      string
+		New, Old interface{}
+	}
+)
+
+var (
+	ErrConfigNotLoaded = errors.New("config file not loaded")
+
+	// Keys applied at runtime,
+	// others take effect after restart.
+	reloadable = map[string]bool{
+		"bucket-batch":               true,
+		"bucket-concurrency":         true,
+		"bucket-frequency":           true,
+		"file-logger.fields":         true,
+		"file-logger.format":         true,
+		"file-tracer.fields":         true,
+		"file-tracer.format":         true,
+		"jaeger-tracer.endpoint":     true,
+		"logger-caller":              true,
+		"logger-caller-skip":         true,
+		"logger-exporter":            true,
+		"logger-level":               true,
+		"logger-levels":              true,
+		"logger-levels-caller":       true,
+		"logger-sampling.enable":     true,
+		"logger-sampling.initial":    true,
+		"logger-sampling.interval":   true,
+		"logger-sampling.span":       true,
+		"logger-sampling.thereafter": true,
+		"open-tracing-sampled":       true,
+		"open-tracing-span-id":       true,
+		"open-tracing-trace-id":      true,
+		"redact.enable":              true,
+		"redact.hash-key":            true,
+		"redact.keys":                true,
+		"redact.mode":                true,
+		"redact.patterns":            true,
+		"redact.rules":               true,
+		"stack-levels":               true,
+		"tracer-exporter":            true,
+		"zipkin-tracer.endpoint":     true,
+	}
+)
+
+// Getter
+
+func (o *config) LoadFrom(path string) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	next := &config{}
+	if err := next.load(path); err != nil {
+		return err
+	}
+	if err := next.env(); err != nil {
+		return err
+	}
+	next.defaults()
+
+	// Apply all fields,
+	// compared with file on next reload.
+	curr := make(map[string]reflect.Value)
+	fields(reflect.ValueOf(o).Elem(), "", curr)
+	for key, v := range next.source {
+		curr[key].Set(reflect.ValueOf(v))
+	}
+
+	o.issues, o.source = next.issues, next.source
+	o.mtime, o.path = next.mtime, next.path
+	o.state()
+	return nil
+}
+
+func (o *config) Modified() bool {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.path == "" {
+		return false
+	}
+	info, err := os.Stat(o.path)
+	return err == nil && !info.ModTime().Equal(o.mtime)
+}
+
+func (o *config) Reload() (changes []Change, err error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.path == "" {
+		return nil, ErrConfigNotLoaded
+	}
+
+	// Build fresh configuration
+	// from config file and environment variables.
+	next := &config{}
+	if err = next.load(o.path); err != nil {
+		return
+	}
+	if err = next.env(); err != nil {
+		return
+	}
+	next.defaults()
+	o.mtime = next.mtime
+
+	if o.Strict {
+		if errs := next.Validate(); len(errs) > 0 {
+			return nil, &ValidationError{Errors: errs}
+		}
+	}
+
+	// Compare with previous loaded,
+	// apply changed fields.
+	curr := make(map[string]reflect.Value)
+	fields(reflect.ValueOf(o).Elem(), "", curr)
+
+	keys := make([]string, 0, len(next.source))
+	for key := range next.source {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	// Changes not applied are kept in source,
+	// reported again until restart.
+	for _, key := range keys {
+		if reflect.DeepEqual(o.source[key], next.source[key]) {
+			continue
+		}
+		c := Change{Applied: reloadable[key], Key: key, New: next.source[key], Old: o.source[key]}
+		if c.Applied {
+			curr[key].Set(reflect.ValueOf(c.New))
+			o.source[key] = c.New
+		}
+		changes = append(changes, c)
+	}
+
+	o.state()
+	return
+}
+
+// Access
+
+// update
+// change fields by callback then publish, serialized with reload so
+// concurrent changes are never published partially.
+func (o *config) update(fn func()) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	fn()
+	o.state()
+}
+
+// current
+// return published values of reloadable keys.
+func (o *config) current() *liveValues { return o.live.Load().(*liveValues) }
+
+// duplicate
+// return copy of map or slice value, other values returned as is.
+func duplicate(v reflect.Value) reflect.Value {
+	switch {
+	case v.Kind() == reflect.Map && !v.IsNil():
+		res := reflect.MakeMapWithSize(v.Type(), v.Len())
+		for it := v.MapRange(); it.Next(); {
+			res.SetMapIndex(it.Key(), it.Value())
+		}
+		return res
+	case v.Kind() == reflect.Slice && !v.IsNil():
+		res := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		reflect.Copy(res, v)
+		return res
+	}
+	return v
+}
+
+// fields
+// collect leaf fields keyed by yaml path, eg. jaeger-tracer.endpoint.
+func fields(rv reflect.Value, prefix string, m map[string]reflect.Value) {
+	rt := rv.Type()
+	for i := 0; i < rt.NumField(); i++ {
+		sf := rt.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		key := strings.Split(sf.Tag.Get("yaml"), ",")[0]
+		if key == "" || key == "-" {
+			continue
+		}
+
+		fv := rv.Field(i)
+		if fv.Kind() == reflect.Ptr && fv.Type().Elem().Kind() == reflect.Struct {
+			if !fv.IsNil() {
+				fields(fv.Elem(), prefix+key+".", m)
+			}
+			continue
+		}
+		m[prefix+key] = fv
+	}
+}
+
+// snapshot
+// return values of leaf fields, compared on reload.
+func (o *config) snapshot() map[string]interface{} {
+	m := make(map[string]reflect.Value)
+	fields(reflect.ValueOf(o).Elem(), "", m)
+
+	res := make(map[string]interface{}, len(m))
+	for key, fv := range m {
+		res[key] = fv.Interface()
+	}
+	return res
+}
+
+// state
+// build values of reloadable keys from fields and publish, level stored as
+// integer, stack levels as bits and prefix rules of logger-levels built.
+func (o *config) state() {
+	v := &liveValues{
+		bucketBatch:        o.BucketBatch,
+		bucketConcurrency:  o.BucketConcurrency,
+		bucketFrequency:    o.BucketFrequency,
+		level:              o.LoggerLevel.Int(),
+		levels:             &levelTable{rules: make(map[string]int)},
+		loggerCaller:       o.LoggerCaller,
+		loggerCallerSkip:   o.LoggerCallerSkip,
+		loggerExporter:     o.LoggerExporter,
+		loggerLevel:        o.LoggerLevel,
+		loggerLevels:       make(map[string]common.Level, len(o.LoggerLevels)),
+		loggerLevelsCaller: o.LoggerLevelsCaller,
+		openTracingSampled: o.OpenTracingSampled,
+		openTracingSpanId:  o.OpenTracingSpanId,
+		openTracingTraceId: o.OpenTracingTraceId,
+		stackLevels:        append([]common.Level(nil), o.StackLevels...),
+		tracerExporter:     o.TracerExporter,
+	}
+
+	// Bit of level integer
+	// set if stack enabled, OFF ignored.
+	for _, l := range o.StackLevels {
+		if n := l.Int(); n > common.Off.Int() {
+			v.stackMask |= 1 << n
+		}
+	}
+
+	for k, l := range o.LoggerLevels {
+		v.loggerLevels[k] = l
+
+		// Accept orders, orders/ and orders/*.
+		if k = strings.TrimSuffix(strings.TrimSuffix(k, "*"), "/"); k != "" {
+			if n := l.Int(); n > 0 {
+				v.levels.rules[k] = n
+			}
+		}
+	}
+
+	// Copy of sections,
+	// redaction rules compiled.
+	if o.FileLogger != nil {
+		c := *o.FileLogger
+		v.fileLogger = &c
+	}
+	if o.FileTracer != nil {
+		c := *o.FileTracer
+		v.fileTracer = &c
+	}
+	if o.JaegerTracer != nil {
+		c := *o.JaegerTracer
+		c.Headers = copyHeaders(c.Headers)
+		v.jaeger = &c
+	}
+	if o.ZipkinTracer != nil {
+		c := *o.ZipkinTracer
+		c.Headers = copyHeaders(c.Headers)
+		v.zipkin = &c
+	}
+	if o.LoggerSampling != nil {
+		c := *o.LoggerSampling
+		v.sampling = &c
+	}
+	if o.Redact != nil {
+		v.redact = &redact{
+			Enable: o.Redact.Enable, HashKey: o.Redact.HashKey, Mode: o.Redact.Mode,
+			Keys:     append([]string(nil), o.Redact.Keys...),
+			Patterns: append([]string(nil), o.Redact.Patterns...),
+			Rules:    append([]string(nil), o.Redact.Rules...),
+		}
+		v.redact.compile()
+	}
+
+	o.live.Store(v)
+}
+
+func copyHeaders(m map[string]string) map[string]string {
+	if m == nil {
+		return nil
+	}
+	res := make(map[string]string, len(m))
+	for k, v := range m {
+		res[k] = v
+	}
+	return res
+}
@@ -0,0 +1,133 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-03-12
+
+package configurer
+
+type (
+	// ConfigTLS
+	// expose tls for network exporters (eg. Jaeger, Zipkin).
+	ConfigTLS interface {
+		GetTLS() TLS
+	}
+
+	// TLS
+	// expose tls configuration methods.
+	TLS interface {
+		GetCaFile() string
+		GetCertFile() string
+		GetEnable() bool
+		GetInsecureSkipVerify() bool
+		GetKeyFile() string
+		GetMinVersion() string
+		GetReloadInterval() int
+		GetServerName() string
+	}
+
+	tlsConfig struct {
+		// CA certificate file which verify server, system pool used if
+		// not specified.
+		CaFile string `yaml:"ca-file"`
+
+		// Client certificate and key file for mutual tls.
+		CertFile string `yaml:"cert-file"`
+		KeyFile  string `yaml:"key-file"`
+
+		// Enable tls settings.
+		// Default: false
+		Enable bool `yaml:"enable"`
+
+		// Skip server certificate verification, development only.
+		// Default: false
+		InsecureSkipVerify bool `yaml:"insecure-skip-verify"`
+
+		// Min tls version.
+		// Accept: 1.0, 1.1, 1.2, 1.3.
+		// Default: 1.2
+		MinVersion string `yaml:"min-version"`
+
+		// Check files modification and reload every 10,000 ms.
+		// Default: 10000 (Millisecond)
+		ReloadInterval int `yaml:"reload-interval"`
+
+		// Server name to verify, request host used if not specified.
+		ServerName string `yaml:"server-name"`
+	}
+)
+
+// Getter
+
+func (o *config) GetTLS() TLS { return o.TLS }
+
+func (o *tlsConfig) GetCaFile() string           { return o.CaFile }
+func (o *tlsConfig) GetCertFile() string         { return o.CertFile }
+func (o *tlsConfig) GetEnable() bool             { return o.Enable }
+func (o *tlsConfig) GetInsecureSkipVerify() bool { return o.InsecureSkipVerify }
+func (o *tlsConfig) GetKeyFile() string          { return o.KeyFile }
+func (o *tlsConfig) GetMinVersion() string       { return o.MinVersion }
+func (o *tlsConfig) GetReloadInterval() int      { return o.ReloadInterval }
+func (o *tlsConfig) GetServerName() string       { return o.ServerName }
+
+// Setter.
+
+func (o *Setter) SetTLSCaFile(s string) *Setter {
+	o.config.TLS.CaFile = s
+	return o
+}
+
+func (o *Setter) SetTLSCertFile(s string) *Setter {
+	o.config.TLS.CertFile = s
+	return o
+}
+
+func (o *Setter) SetTLSEnable(b bool) *Setter {
+	o.config.TLS.Enable = b
+	return o
+}
+
+func (o *Setter) SetTLSInsecureSkipVerify(b bool) *Setter {
+	o.config.TLS.InsecureSkipVerify = b
+	return o
+}
+
+func (o *Setter) SetTLSKeyFile(s string) *Setter {
+	o.config.TLS.KeyFile = s
+	return o
+}
+
+func (o *Setter) SetTLSMinVersion(s string) *Setter {
+	o.config.TLS.MinVersion = s
+	return o
+}
+
+func (o *Setter) SetTLSReloadInterval(n int) *Setter {
+	o.config.TLS.ReloadInterval = n
+	return o
+}
+
+func (o *Setter) SetTLSServerName(s string) *Setter {
+	o.config.TLS.ServerName = s
+	return o
+}
+
+// Defaults
+
+func (o *tlsConfig) initDefaults() {
+	if o.MinVersion == "" {
+		o.MinVersion = defaultTLSMinVersion
+	}
+	if o.ReloadInterval == 0 {
+		o.ReloadInterval = defaultTLSReloadInterval
+	}
+}
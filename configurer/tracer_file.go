@@ -15,6 +15,11 @@
 
 package configurer
 
+import (
+	"github.com/fuyibing/log/v5/common"
+	"os"
+)
+
 type (
 	// ConfigTracerFile
 	// expose file adapter for tracer.
@@ -25,10 +30,24 @@ type (
 	// FileTracer
 	// expose file tracer configuration methods.
 	FileTracer interface {
+		GetDirMode() os.FileMode
 		GetExt() string
+
+		// GetFields
+		// return placement of key/value pairs in json format.
+		GetFields() common.FileFields
+
+		GetFileMode() os.FileMode
 		GetFolder() string
+
+		// GetFormat
+		// return output format, text or json lines.
+		GetFormat() common.FileFormat
+
 		GetName() string
 		GetPath() string
+		GetSync() common.SyncPolicy
+		GetSyncInterval() int
 	}
 
 	fileTracer struct {
@@ -36,43 +55,129 @@ type (
 		Folder string `yaml:"folder"`
 		Name   string `yaml:"name"`
 		Path   string `yaml:"path"`
+
+		// Directory and file permissions, octal string.
+		// Default: 0755, 0644
+		DirMode  string `yaml:"dir-mode"`
+		FileMode string `yaml:"file-mode"`
+
+		// Output format, json writes one object per line.
+		// Accept: text, json.
+		// Default: text
+		Format common.FileFormat `yaml:"format"`
+
+		// Key/value pairs of json format, flat merged into record.
+		// Accept: nested, flat.
+		// Default: nested
+		Fields common.FileFields `yaml:"fields"`
+
+		// Sync policy.
+		// Accept: always, interval, never.
+		// Default: interval
+		Sync common.SyncPolicy `yaml:"sync"`
+
+		// Flush buffer per 1000 ms if sync policy is not always.
+		// Default: 1000 (Millisecond)
+		SyncInterval int `yaml:"sync-interval"`
 	}
 )
 
 // Getter
 
-func (o *config) GetFileTracer() FileTracer { return o.FileTracer }
+func (o *config) GetFileTracer() FileTracer { return o.current().fileTracer }
 
-func (o *fileTracer) GetExt() string    { return o.Ext }
-func (o *fileTracer) GetFolder() string { return o.Folder }
-func (o *fileTracer) GetName() string   { return o.Name }
-func (o *fileTracer) GetPath() string   { return o.Path }
+func (o *fileTracer) GetDirMode() os.FileMode      { return fileMode(o.DirMode, 0755) }
+func (o *fileTracer) GetExt() string               { return o.Ext }
+func (o *fileTracer) GetFields() common.FileFields { return o.Fields }
+func (o *fileTracer) GetFileMode() os.FileMode     { return fileMode(o.FileMode, 0644) }
+func (o *fileTracer) GetFolder() string            { return o.Folder }
+func (o *fileTracer) GetFormat() common.FileFormat { return o.Format }
+func (o *fileTracer) GetName() string              { return o.Name }
+func (o *fileTracer) GetPath() string              { return o.Path }
+func (o *fileTracer) GetSync() common.SyncPolicy   { return o.Sync }
+func (o *fileTracer) GetSyncInterval() int         { return o.SyncInterval }
 
 // Setter.
 
+func (o *Setter) SetFileTracerDirMode(s string) *Setter {
+	o.config.update(func() {
+		o.config.FileTracer.DirMode = s
+	})
+	return o
+}
+
 func (o *Setter) SetFileTracerExt(s string) *Setter {
-	o.config.FileTracer.Ext = s
+	o.config.update(func() {
+		o.config.FileTracer.Ext = s
+	})
+	return o
+}
+
+func (o *Setter) SetFileTracerFields(v common.FileFields) *Setter {
+	o.config.update(func() {
+		o.config.FileTracer.Fields = v
+	})
+	return o
+}
+
+func (o *Setter) SetFileTracerFileMode(s string) *Setter {
+	o.config.update(func() {
+		o.config.FileTracer.FileMode = s
+	})
 	return o
 }
 
 func (o *Setter) SetFileTracerFolder(s string) *Setter {
-	o.config.FileTracer.Folder = s
+	o.config.update(func() {
+		o.config.FileTracer.Folder = s
+	})
+	return o
+}
+
+func (o *Setter) SetFileTracerFormat(v common.FileFormat) *Setter {
+	o.config.update(func() {
+		o.config.FileTracer.Format = v
+	})
 	return o
 }
 
 func (o *Setter) SetFileTracerName(s string) *Setter {
-	o.config.FileTracer.Name = s
+	o.config.update(func() {
+		o.config.FileTracer.Name = s
+	})
 	return o
 }
 
 func (o *Setter) SetFileTracerPath(s string) *Setter {
-	o.config.FileTracer.Path = s
+	o.config.update(func() {
+		o.config.FileTracer.Path = s
+	})
+	return o
+}
+
+func (o *Setter) SetFileTracerSync(v common.SyncPolicy) *Setter {
+	o.config.update(func() {
+		o.config.FileTracer.Sync = v
+	})
+	return o
+}
+
+func (o *Setter) SetFileTracerSyncInterval(n int) *Setter {
+	o.config.update(func() {
+		o.config.FileTracer.SyncInterval = n
+	})
 	return o
 }
 
 // Defaults
 
 func (o *fileTracer) initDefaults() {
+	if o.Fields == "" {
+		o.Fields = defaultFileTracerFields
+	}
+	if o.Format == "" {
+		o.Format = defaultFileTracerFormat
+	}
 	if o.Ext == "" {
 		o.Ext = defaultFileTracerExt
 	}
@@ -85,4 +190,16 @@ func (o *fileTracer) initDefaults() {
 	if o.Path == "" {
 		o.Path = defaultFileTracerPath
 	}
+	if o.DirMode == "" {
+		o.DirMode = defaultFileTracerDirMode
+	}
+	if o.FileMode == "" {
+		o.FileMode = defaultFileTracerFileMode
+	}
+	if o.Sync == "" {
+		o.Sync = defaultFileTracerSync
+	}
+	if o.SyncInterval <= 0 {
+		o.SyncInterval = defaultFileTracerSyncInterval
+	}
 }
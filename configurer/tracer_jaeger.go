@@ -15,6 +15,10 @@
 
 package configurer
 
+import (
+	"github.com/fuyibing/log/v5/common"
+)
+
 type (
 	// ConfigTracerJaeger
 	// expose jaeger adapter for tracer.
@@ -25,13 +29,25 @@ type (
 	// JaegerTracer
 	// expose jaeger tracer configuration methods.
 	JaegerTracer interface {
+		GetBearerToken() string
+		GetCompression() common.Compression
 		GetContentType() string
 		GetEndpoint() string
+		GetHeaders() map[string]string
 		GetPassword() string
+		GetTimeout() int
 		GetUsername() string
 	}
 
 	jaegerTracer struct {
+		// Token of bearer authorization, sent as Authorization header.
+		BearerToken string `yaml:"bearer-token"`
+
+		// Request body compression.
+		// Accept: gzip, none.
+		// Default: none
+		Compression common.Compression `yaml:"compression"`
+
 		// API Content type.
 		// Default: application/x-thrift
 		ContentType string `yaml:"content-type"`
@@ -40,6 +56,13 @@ type (
 		// Example: http://localhost:14268/api/traces
 		Endpoint string `yaml:"endpoint"`
 
+		// Custom request headers, eg. API key of gateway.
+		Headers map[string]string `yaml:"headers"`
+
+		// Request timeout.
+		// Default: 5000 (Millisecond)
+		Timeout int `yaml:"timeout"`
+
 		Username string `yaml:"username"`
 		Password string `yaml:"password"`
 	}
@@ -47,39 +70,88 @@ type (
 
 // Getter
 
-func (o *config) GetJaegerTracer() JaegerTracer { return o.JaegerTracer }
+func (o *config) GetJaegerTracer() JaegerTracer { return o.current().jaeger }
 
-func (o *jaegerTracer) GetContentType() string { return o.ContentType }
-func (o *jaegerTracer) GetEndpoint() string    { return o.Endpoint }
-func (o *jaegerTracer) GetPassword() string    { return o.Password }
-func (o *jaegerTracer) GetUsername() string    { return o.Username }
+func (o *jaegerTracer) GetBearerToken() string             { return o.BearerToken }
+func (o *jaegerTracer) GetCompression() common.Compression { return o.Compression }
+func (o *jaegerTracer) GetContentType() string             { return o.ContentType }
+func (o *jaegerTracer) GetEndpoint() string                { return o.Endpoint }
+func (o *jaegerTracer) GetHeaders() map[string]string      { return o.Headers }
+func (o *jaegerTracer) GetPassword() string                { return o.Password }
+func (o *jaegerTracer) GetTimeout() int                    { return o.Timeout }
+func (o *jaegerTracer) GetUsername() string                { return o.Username }
 
 // Setter.
 
+func (o *Setter) SetJaegerTracerBearerToken(s string) *Setter {
+	o.config.update(func() {
+		o.config.JaegerTracer.BearerToken = s
+	})
+	return o
+}
+
+func (o *Setter) SetJaegerTracerCompression(c common.Compression) *Setter {
+	o.config.update(func() {
+		o.config.JaegerTracer.Compression = c
+	})
+	return o
+}
+
 func (o *Setter) SetJaegerTracerContentType(s string) *Setter {
-	o.config.JaegerTracer.ContentType = s
+	o.config.update(func() {
+		o.config.JaegerTracer.ContentType = s
+	})
 	return o
 }
 
 func (o *Setter) SetJaegerTracerEndpoint(s string) *Setter {
-	o.config.JaegerTracer.Endpoint = s
+	o.config.update(func() {
+		o.config.JaegerTracer.Endpoint = s
+	})
 	return o
 }
 
 func (o *Setter) SetJaegerTracerPassword(s string) *Setter {
-	o.config.JaegerTracer.Password = s
+	o.config.update(func() {
+		o.config.JaegerTracer.Password = s
+	})
 	return o
 }
 
 func (o *Setter) SetJaegerTracerUsername(s string) *Setter {
-	o.config.JaegerTracer.Username = s
+	o.config.update(func() {
+		o.config.JaegerTracer.Username = s
+	})
+	return o
+}
+
+func (o *Setter) SetJaegerTracerHeader(k, v string) *Setter {
+	o.config.update(func() {
+		if o.config.JaegerTracer.Headers == nil {
+			o.config.JaegerTracer.Headers = make(map[string]string)
+		}
+		o.config.JaegerTracer.Headers[k] = v
+	})
+	return o
+}
+
+func (o *Setter) SetJaegerTracerTimeout(n int) *Setter {
+	o.config.update(func() {
+		o.config.JaegerTracer.Timeout = n
+	})
 	return o
 }
 
 // Access.
 
 func (o *jaegerTracer) initDefaults() {
+	if o.Compression == "" {
+		o.Compression = defaultJaegerTracerCompression
+	}
 	if o.ContentType == "" {
 		o.ContentType = "application/x-thrift"
 	}
+	if o.Timeout == 0 {
+		o.Timeout = defaultJaegerTracerTimeout
+	}
 }
@@ -15,6 +15,10 @@
 
 package configurer
 
+import (
+	"github.com/fuyibing/log/v5/common"
+)
+
 type (
 	// ConfigTracerZipkin
 	// expose zipkin adapter for tracer.
@@ -25,11 +29,23 @@ type (
 	// ZipkinTracer
 	// expose zipkin tracer configuration methods.
 	ZipkinTracer interface {
+		GetBearerToken() string
+		GetCompression() common.Compression
 		GetContentType() string
 		GetEndpoint() string
+		GetHeaders() map[string]string
+		GetTimeout() int
 	}
 
 	zipkinTracer struct {
+		// Token of bearer authorization, sent as Authorization header.
+		BearerToken string `yaml:"bearer-token"`
+
+		// Request body compression.
+		// Accept: gzip, none.
+		// Default: none
+		Compression common.Compression `yaml:"compression"`
+
 		// API Content type.
 		// Default: application/json
 		ContentType string `yaml:"content-type"`
@@ -37,32 +53,84 @@ type (
 		// API Address.
 		// Example: http://localhost:9411/api/v2/spans
 		Endpoint string `yaml:"endpoint"`
+
+		// Custom request headers, eg. API key of gateway.
+		Headers map[string]string `yaml:"headers"`
+
+		// Request timeout.
+		// Default: 5000 (Millisecond)
+		Timeout int `yaml:"timeout"`
 	}
 )
 
 // Getter
 
-func (o *config) GetZipkinTracer() ZipkinTracer { return o.ZipkinTracer }
+func (o *config) GetZipkinTracer() ZipkinTracer { return o.current().zipkin }
 
-func (o *zipkinTracer) GetContentType() string { return o.ContentType }
-func (o *zipkinTracer) GetEndpoint() string    { return o.Endpoint }
+func (o *zipkinTracer) GetBearerToken() string             { return o.BearerToken }
+func (o *zipkinTracer) GetCompression() common.Compression { return o.Compression }
+func (o *zipkinTracer) GetContentType() string             { return o.ContentType }
+func (o *zipkinTracer) GetEndpoint() string                { return o.Endpoint }
+func (o *zipkinTracer) GetHeaders() map[string]string      { return o.Headers }
+func (o *zipkinTracer) GetTimeout() int                    { return o.Timeout }
 
 // Setter.
 
+func (o *Setter) SetZipkinTracerBearerToken(s string) *Setter {
+	o.config.update(func() {
+		o.config.ZipkinTracer.BearerToken = s
+	})
+	return o
+}
+
+func (o *Setter) SetZipkinTracerCompression(c common.Compression) *Setter {
+	o.config.update(func() {
+		o.config.ZipkinTracer.Compression = c
+	})
+	return o
+}
+
 func (o *Setter) SetZipkinTracerContentType(s string) *Setter {
-	o.config.ZipkinTracer.ContentType = s
+	o.config.update(func() {
+		o.config.ZipkinTracer.ContentType = s
+	})
 	return o
 }
 
 func (o *Setter) SetZipkinTracerEndpoint(s string) *Setter {
-	o.config.ZipkinTracer.Endpoint = s
+	o.config.update(func() {
+		o.config.ZipkinTracer.Endpoint = s
+	})
+	return o
+}
+
+func (o *Setter) SetZipkinTracerHeader(k, v string) *Setter {
+	o.config.update(func() {
+		if o.config.ZipkinTracer.Headers == nil {
+			o.config.ZipkinTracer.Headers = make(map[string]string)
+		}
+		o.config.ZipkinTracer.Headers[k] = v
+	})
+	return o
+}
+
+func (o *Setter) SetZipkinTracerTimeout(n int) *Setter {
+	o.config.update(func() {
+		o.config.ZipkinTracer.Timeout = n
+	})
 	return o
 }
 
 // Access.
 
 func (o *zipkinTracer) initDefaults() {
+	if o.Compression == "" {
+		o.Compression = defaultZipkinTracerCompression
+	}
 	if o.ContentType == "" {
 		o.ContentType = "application/json"
 	}
+	if o.Timeout == 0 {
+		o.Timeout = defaultZipkinTracerTimeout
+	}
 }
@@ -0,0 +1,423 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-03-17
+
+package configurer
+
+import (
+	"fmt"
+	"github.com/fuyibing/log/v5/common"
+	"gopkg.in/yaml.v3"
+	"net/url"
+	"path"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+type (
+	// ConfigValidate
+	// expose validation methods.
+	ConfigValidate interface {
+		GetStrict() bool
+
+		// Validate
+		// return all problems of configuration, include syntax errors,
+		// unknown keys and invalid values. Nil returned if valid.
+		Validate() []error
+	}
+
+	// ValidationError
+	// raised by manager start in strict mode, contains all problems.
+	ValidationError struct {
+		Errors []error
+	}
+)
+
+var (
+	exporters = struct {
+		sync.RWMutex
+		loggers, tracers map[string]bool
+	}{loggers: make(map[string]bool), tracers: make(map[string]bool)}
+
+	// Keys of application
+	// sharing config/log.yaml.
+	ignoredKeys = map[string]bool{"service-name": true, "service-port": true, "service-version": true}
+
+	validBucketTypes    = []common.BucketType{common.BucketRing, common.BucketSlice}
+	validFileFields     = []common.FileFields{common.FileFieldsFlat, common.FileFieldsNested}
+	validFileFormats    = []common.FileFormat{common.FileFormatJson, common.FileFormatText}
+	validCompressions   = []common.Compression{common.CompressionGzip, common.CompressionNone}
+	validRedactModes    = []string{RedactDrop, RedactHash, RedactMask}
+	validOverflowPolicy = []common.OverflowPolicy{common.OverflowBlock, common.OverflowDropByLevel, common.OverflowDropNewest, common.OverflowDropOldest}
+	validSyncPolicies   = []common.SyncPolicy{common.SyncAlways, common.SyncInterval, common.SyncNever}
+	validTLSVersions    = []string{"1.0", "1.1", "1.2", "1.3"}
+
+	// Reference time which every element differ, layout without
+	// any time element formatted as itself.
+	layoutTime = time.Date(2001, 2, 3, 4, 5, 6, 0, time.UTC)
+)
+
+// RegisterLoggerExporter
+// register logger exporter names which accepted by Validate.
+func RegisterLoggerExporter(names ...string) {
+	exporters.Lock()
+	defer exporters.Unlock()
+	for _, name := range names {
+		exporters.loggers[name] = true
+	}
+}
+
+// RegisterTracerExporter
+// register tracer exporter names which accepted by Validate.
+func RegisterTracerExporter(names ...string) {
+	exporters.Lock()
+	defer exporters.Unlock()
+	for _, name := range names {
+		exporters.tracers[name] = true
+	}
+}
+
+// Validate
+// validate global configuration.
+func Validate() []error { return Config.Validate() }
+
+func (e *ValidationError) Error() string {
+	list := make([]string, 0, len(e.Errors))
+	for _, err := range e.Errors {
+		list = append(list, err.Error())
+	}
+	return fmt.Sprintf("invalid config: %s", strings.Join(list, "; "))
+}
+
+// Getter
+
+func (o *config) GetStrict() bool { return o.Strict }
+
+func (o *config) Validate() (errs []error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	add := func(key, format string, args ...interface{}) {
+		errs = append(errs, fmt.Errorf("%s: %s", key, fmt.Sprintf(format, args...)))
+	}
+
+	// Problems found
+	// when config file and environment loaded.
+	errs = append(errs, o.issues...)
+
+	// Enum values.
+
+	if o.LoggerLevel.Int() == 0 {
+		add("logger-level", "unknown level %q", o.LoggerLevel)
+	}
+	for k, l := range o.LoggerLevels {
+		if l.Int() == 0 {
+			add("logger-levels."+k, "unknown level %q", l)
+		}
+	}
+	for _, l := range o.StackLevels {
+		if l.Int() == 0 {
+			add("stack-levels", "unknown level %q", l)
+		}
+	}
+	if !validEnum(o.BucketType, validBucketTypes) {
+		add("bucket-type", "unknown type %q, accept: %v", o.BucketType, validBucketTypes)
+	}
+	if !validEnum(o.OverflowPolicy, validOverflowPolicy) {
+		add("overflow-policy", "unknown policy %q, accept: %v", o.OverflowPolicy, validOverflowPolicy)
+	}
+	if o.BucketType == common.BucketRing && o.OverflowPolicy != common.OverflowDropNewest {
+		add("overflow-policy", "policy %q not supported by ring bucket, accept: %s", o.OverflowPolicy, common.OverflowDropNewest)
+	}
+	if o.BucketSpill.Enable && o.OverflowPolicy != common.OverflowDropNewest {
+		add("overflow-policy", "policy %q never spill to disk, bucket-spill accept: %s", o.OverflowPolicy, common.OverflowDropNewest)
+	}
+	if !validEnum(o.FileLogger.Sync, validSyncPolicies) {
+		add("file-logger.sync", "unknown policy %q, accept: %v", o.FileLogger.Sync, validSyncPolicies)
+	}
+	if !validEnum(o.FileTracer.Sync, validSyncPolicies) {
+		add("file-tracer.sync", "unknown policy %q, accept: %v", o.FileTracer.Sync, validSyncPolicies)
+	}
+	if !validEnum(o.FileLogger.Format, validFileFormats) {
+		add("file-logger.format", "unknown format %q, accept: %v", o.FileLogger.Format, validFileFormats)
+	}
+	if !validEnum(o.FileTracer.Format, validFileFormats) {
+		add("file-tracer.format", "unknown format %q, accept: %v", o.FileTracer.Format, validFileFormats)
+	}
+	if !validEnum(o.FileLogger.Fields, validFileFields) {
+		add("file-logger.fields", "unknown fields %q, accept: %v", o.FileLogger.Fields, validFileFields)
+	}
+	if !validEnum(o.FileTracer.Fields, validFileFields) {
+		add("file-tracer.fields", "unknown fields %q, accept: %v", o.FileTracer.Fields, validFileFields)
+	}
+	if !validEnum(o.JaegerTracer.Compression, validCompressions) {
+		add("jaeger-tracer.compression", "unknown compression %q, accept: %v", o.JaegerTracer.Compression, validCompressions)
+	}
+	if !validEnum(o.ZipkinTracer.Compression, validCompressions) {
+		add("zipkin-tracer.compression", "unknown compression %q, accept: %v", o.ZipkinTracer.Compression, validCompressions)
+	}
+	if !validEnum(o.TLS.MinVersion, validTLSVersions) {
+		add("tls.min-version", "unknown version %q, accept: %v", o.TLS.MinVersion, validTLSVersions)
+	}
+	if !validEnum(strings.ToLower(o.Redact.Mode), validRedactModes) {
+		add("redact.mode", "unknown mode %q, accept: %v", o.Redact.Mode, validRedactModes)
+	}
+	if o.Redact.Enable && strings.ToLower(o.Redact.Mode) == RedactHash && o.Redact.HashKey == "" {
+		add("redact.hash-key", "required by hash mode, hashes differ between processes")
+	}
+
+	// Exporter names,
+	// skipped if registry not populated.
+
+	exporters.RLock()
+	if len(exporters.loggers) > 0 && !exporters.loggers[o.LoggerExporter] {
+		add("logger-exporter", "unknown exporter %q, accept: %v", o.LoggerExporter, sortedKeys(exporters.loggers))
+	}
+	if len(exporters.tracers) > 0 {
+		for key, name := range map[string]string{
+			"tracer-exporter":          o.TracerExporter,
+			"http-retry.dead-letter":   o.HttpRetry.DeadLetter,
+			"circuit-breaker.fallback": o.CircuitBreaker.Fallback,
+		} {
+			if (name != "" || key == "tracer-exporter") && !exporters.tracers[name] {
+				add(key, "unknown exporter %q, accept: %v", name, sortedKeys(exporters.tracers))
+			}
+		}
+	}
+	exporters.RUnlock()
+
+	// Endpoints,
+	// required if exporter used.
+
+	for key, s := range map[string]struct {
+		endpoint string
+		name     string
+	}{
+		"jaeger-tracer.endpoint": {o.JaegerTracer.Endpoint, "jaeger"},
+		"zipkin-tracer.endpoint": {o.ZipkinTracer.Endpoint, "zipkin"},
+	} {
+		if s.endpoint == "" {
+			if o.TracerExporter == s.name || o.HttpRetry.DeadLetter == s.name || o.CircuitBreaker.Fallback == s.name {
+				add(key, "required by %s exporter", s.name)
+			}
+			continue
+		}
+		if err := validEndpoint(s.endpoint); err != nil {
+			add(key, "%v", err)
+		}
+	}
+
+	// Numeric ranges.
+
+	positive := map[string]int64{
+		"bucket-batch":                      int64(o.BucketBatch),
+		"bucket-capacity":                   int64(o.BucketCapacity),
+		"bucket-concurrency":                int64(o.BucketConcurrency),
+		"bucket-frequency":                  int64(o.BucketFrequency),
+		"bucket-spill.segment-size":         o.BucketSpill.SegmentSize,
+		"circuit-breaker.failure-threshold": int64(o.CircuitBreaker.FailureThreshold),
+		"file-logger.sync-interval":         int64(o.FileLogger.SyncInterval),
+		"file-tracer.sync-interval":         int64(o.FileTracer.SyncInterval),
+		"hot-reload.interval":               int64(o.HotReload.Interval),
+		"http-retry.max-attempts":           int64(o.HttpRetry.MaxAttempts),
+		"jaeger-tracer.timeout":             int64(o.JaegerTracer.Timeout),
+		"logger-sampling.initial":           int64(o.LoggerSampling.Initial),
+		"logger-sampling.interval":          int64(o.LoggerSampling.Interval),
+		"logger-sampling.thereafter":        int64(o.LoggerSampling.Thereafter),
+		"zipkin-tracer.timeout":             int64(o.ZipkinTracer.Timeout),
+	}
+	for key, n := range positive {
+		if n <= 0 {
+			add(key, "must be greater than 0, got %d", n)
+		}
+	}
+
+	nonNegative := map[string]int64{
+		"bucket-spill.max-size":      o.BucketSpill.MaxSize,
+		"circuit-breaker.cooldown":   int64(o.CircuitBreaker.Cooldown),
+		"http-retry.deadline":        int64(o.HttpRetry.Deadline),
+		"http-retry.initial-backoff": int64(o.HttpRetry.InitialBackoff),
+		"logger-caller-skip":         int64(o.LoggerCallerSkip),
+		"max-batch-bytes":            int64(o.MaxBatchBytes),
+		"overflow-timeout":           int64(o.OverflowTimeout),
+		"tls.reload-interval":        int64(o.TLS.ReloadInterval),
+	}
+	for key, n := range nonNegative {
+		if n < 0 {
+			add(key, "must not be negative, got %d", n)
+		}
+	}
+
+	if o.HttpRetry.MaxBackoff < o.HttpRetry.InitialBackoff {
+		add("http-retry.max-backoff", "must not be less than initial-backoff %d, got %d", o.HttpRetry.InitialBackoff, o.HttpRetry.MaxBackoff)
+	}
+	if o.HttpRetry.Jitter < 0 || o.HttpRetry.Jitter > 1 {
+		add("http-retry.jitter", "must between 0 and 1, got %v", o.HttpRetry.Jitter)
+	}
+	if o.BucketSpill.MaxSize > 0 && o.BucketSpill.MaxSize < o.BucketSpill.SegmentSize {
+		add("bucket-spill.max-size", "must not be less than segment-size %d, got %d", o.BucketSpill.SegmentSize, o.BucketSpill.MaxSize)
+	}
+
+	// File modes and
+	// time layouts.
+
+	for key, s := range map[string]string{
+		"file-logger.dir-mode":  o.FileLogger.DirMode,
+		"file-logger.file-mode": o.FileLogger.FileMode,
+		"file-tracer.dir-mode":  o.FileTracer.DirMode,
+		"file-tracer.file-mode": o.FileTracer.FileMode,
+	} {
+		if _, err := strconv.ParseUint(s, 8, 32); err != nil {
+			add(key, "invalid octal permission %q", s)
+		}
+	}
+
+	for key, s := range map[string]string{
+		"file-logger.folder": o.FileLogger.Folder,
+		"file-logger.name":   o.FileLogger.Name,
+		"file-tracer.folder": o.FileTracer.Folder,
+		"file-tracer.name":   o.FileTracer.Name,
+	} {
+		if layoutTime.Format(s) == s {
+			add(key, "no time element in layout %q, eg. 2006-01-02", s)
+		}
+	}
+
+	// Redaction patterns
+	// must be compiled.
+
+	for _, k := range o.Redact.Keys {
+		if _, err := path.Match(strings.ToLower(k), ""); err != nil {
+			add("redact.keys", "invalid pattern %q", k)
+		}
+	}
+	for _, p := range o.Redact.Patterns {
+		if _, err := regexp.Compile(p); err != nil {
+			add("redact.patterns", "invalid pattern %q: %v", p, err)
+		}
+	}
+	for _, name := range o.Redact.Rules {
+		if _, ok := redactRules[strings.ToLower(name)]; !ok {
+			add("redact.rules", "unknown rule %q, accept: [card email token]", name)
+		}
+	}
+
+	// TLS files
+	// must be paired and loadable.
+
+	if o.TLS.Enable {
+		if (o.TLS.CertFile == "") != (o.TLS.KeyFile == "") {
+			add("tls", "cert-file and key-file must be specified together")
+		} else if _, err := common.NewTLSConfig("configurer", o.TLS, nil); err != nil {
+			add("tls", "%v", err)
+		}
+	}
+
+	sort.SliceStable(errs[len(o.issues):], func(i, j int) bool {
+		return errs[len(o.issues)+i].Error() < errs[len(o.issues)+j].Error()
+	})
+	return
+}
+
+// Setter
+
+func (o *Setter) SetStrict(b bool) *Setter { o.config.Strict = b; return o }
+
+// Access
+
+// issue
+// record problem found when config loaded, reported by Validate.
+func (o *config) issue(format string, args ...interface{}) {
+	o.issues = append(o.issues, fmt.Errorf(format, args...))
+}
+
+// unknown
+// record keys of config file which not defined by configuration fields,
+// keys of application shared the file are ignored.
+func (o *config) unknown(path string, buf []byte) {
+	var m map[string]interface{}
+	if yaml.Unmarshal(buf, &m) != nil {
+		return
+	}
+	for key := range ignoredKeys {
+		delete(m, key)
+	}
+	for _, key := range unknownKeys(m, reflect.TypeOf(o).Elem(), "") {
+		o.issue("%s: unknown key in %s", key, path)
+	}
+}
+
+func unknownKeys(m map[string]interface{}, rt reflect.Type, prefix string) (keys []string) {
+	fields := make(map[string]reflect.Type)
+	for i := 0; i < rt.NumField(); i++ {
+		if sf := rt.Field(i); sf.IsExported() {
+			if key := strings.Split(sf.Tag.Get("yaml"), ",")[0]; key != "" && key != "-" {
+				fields[key] = sf.Type
+			}
+		}
+	}
+
+	for key, v := range m {
+		ft, ok := fields[key]
+		if !ok {
+			keys = append(keys, prefix+key)
+			continue
+		}
+		if ft.Kind() == reflect.Ptr && ft.Elem().Kind() == reflect.Struct {
+			if sub, ok := v.(map[string]interface{}); ok {
+				keys = append(keys, unknownKeys(sub, ft.Elem(), prefix+key+".")...)
+			}
+		}
+	}
+
+	sort.Strings(keys)
+	return
+}
+
+func sortedKeys(m map[string]bool) []string {
+	list := make([]string, 0, len(m))
+	for k := range m {
+		list = append(list, k)
+	}
+	sort.Strings(list)
+	return list
+}
+
+func validEndpoint(s string) error {
+	u, err := url.Parse(s)
+	if err != nil {
+		return err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("scheme http or https required: %s", s)
+	}
+	if u.Host == "" {
+		return fmt.Errorf("host required: %s", s)
+	}
+	return nil
+}
+
+func validEnum[T comparable](v T, list []T) bool {
+	for _, x := range list {
+		if v == x {
+			return true
+		}
+	}
+	return false
+}
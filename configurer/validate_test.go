@@ -0,0 +1,65 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-03-17
+
+package configurer
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// TestValidateShipped
+// config file shipped with repository must pass validation, otherwise
+// strict mode panics on start.
+func TestValidateShipped(t *testing.T) {
+	testRegister()
+
+	c := (&config{}).defaults()
+	if err := c.LoadFrom("../config/log.yaml"); err != nil {
+		t.Fatalf("load shipped config: %v", err)
+	}
+	for _, err := range c.Validate() {
+		t.Errorf("shipped config: %v", err)
+	}
+}
+
+// TestValidateUnknown
+// keys not declared by configuration fields are reported, keys of
+// application shared the file are not.
+func TestValidateUnknown(t *testing.T) {
+	testRegister()
+
+	path := filepath.Join(t.TempDir(), "log.yaml")
+	if err := os.WriteFile(path, []byte("service-name: app\njaeger-tracer:\n  endpont: x\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	c := (&config{}).defaults()
+	if err := c.LoadFrom(path); err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	errs := c.Validate()
+	if len(errs) != 1 {
+		t.Fatalf("expected 1 error, got %d: %v", len(errs), errs)
+	}
+}
+
+// testRegister
+// register builtin exporter names, same as root package.
+func testRegister() {
+	RegisterLoggerExporter("file", "memory", "term")
+	RegisterTracerExporter("file", "jaeger", "memory", "term", "zipkin")
+}
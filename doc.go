@@ -24,6 +24,11 @@
 //   // Called before main function quit.
 //   log.Logger.Stop()
 //
+// Flush or shutdown with deadline.
+//   // Serverless handler or CLI tool.
+//   log.Manager.Flush(ctx)
+//   log.Manager.Shutdown(ctx)
+//
 // Example.
 //   func main(){
 //       log.Logger.Start(ctx)
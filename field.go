@@ -27,39 +27,135 @@ type (
 	//   log.Field{"key":"value"}.
 	//       Debug("message")
 	Field map[string]interface{}
+
+	// FieldLogger
+	// send logs with fields to bound manager.
+	FieldLogger interface {
+		Debug(format string, args ...interface{})
+
+		// Err
+		// return field logger with error, cause chain recorded as
+		// error.chain and stack of error used if carried.
+		Err(err error) FieldLogger
+
+		Error(format string, args ...interface{})
+		Fatal(format string, args ...interface{})
+		Info(format string, args ...interface{})
+
+		// Named
+		// return field logger with name, level resolved by logger-levels.
+		Named(name string) FieldLogger
+
+		Warn(format string, args ...interface{})
+	}
+
+	boundField struct {
+		err     error
+		field   Field
+		manager Management
+		name    string
+	}
 )
 
+// Err
+// return logger with error of global Manager.
+//
+//   log.Err(err).
+//       Error("create order")
+func Err(err error) FieldLogger { return &boundField{err: err, manager: Manager} }
+
+// Named
+// return logger with name, level resolved by logger-levels of global
+// Manager.
+//
+//   log.Named("orders/payment").
+//       Debug("message")
+func Named(name string) FieldLogger { return &boundField{manager: Manager, name: name} }
+
+// Bind
+// return field logger bound to specified manager, which created by New.
+//
+//   log.Field{"key":"value"}.Bind(m).
+//       Info("message")
+func (o Field) Bind(m Management) FieldLogger { return &boundField{field: o, manager: m} }
+
 // Debug
 // send DEBUG level log to executor.
 func (o Field) Debug(format string, args ...interface{}) {
-	sendLog(o, common.Debug, format, args...)
+	sendLog(Manager, o, "", nil, common.Debug, format, args...)
+}
+
+// Err
+// return field logger with error.
+//
+//   log.Field{"key":"value"}.Err(err).
+//       Error("create order")
+func (o Field) Err(err error) FieldLogger {
+	return &boundField{err: err, field: o, manager: Manager}
 }
 
 // Error
 // send ERROR level log to executor.
 func (o Field) Error(format string, args ...interface{}) {
-	sendLog(o, common.Error, format, args...)
+	sendLog(Manager, o, "", nil, common.Error, format, args...)
 }
 
 // Fatal
 // send FATAL level log to executor.
 func (o Field) Fatal(format string, args ...interface{}) {
-	sendLog(o, common.Fatal, format, args...)
+	sendLog(Manager, o, "", nil, common.Fatal, format, args...)
 }
 
 // Info
 // send INFO level log to executor.
 func (o Field) Info(format string, args ...interface{}) {
-	sendLog(o, common.Info, format, args...)
+	sendLog(Manager, o, "", nil, common.Info, format, args...)
+}
+
+// Named
+// return field logger with name, level resolved by logger-levels.
+//
+//   log.Field{"key":"value"}.Named("orders").
+//       Debug("message")
+func (o Field) Named(name string) FieldLogger {
+	return &boundField{field: o, manager: Manager, name: name}
 }
 
 // Warn
 // send WARN level log to executor.
 func (o Field) Warn(format string, args ...interface{}) {
-	sendLog(o, common.Warn, format, args...)
+	sendLog(Manager, o, "", nil, common.Warn, format, args...)
 }
 
-func sendLog(field Field, level common.Level, format string, args ...interface{}) {
+func (o *boundField) Debug(format string, args ...interface{}) {
+	sendLog(o.manager, o.field, o.name, o.err, common.Debug, format, args...)
+}
+
+func (o *boundField) Err(err error) FieldLogger {
+	return &boundField{err: err, field: o.field, manager: o.manager, name: o.name}
+}
+
+func (o *boundField) Error(format string, args ...interface{}) {
+	sendLog(o.manager, o.field, o.name, o.err, common.Error, format, args...)
+}
+
+func (o *boundField) Fatal(format string, args ...interface{}) {
+	sendLog(o.manager, o.field, o.name, o.err, common.Fatal, format, args...)
+}
+
+func (o *boundField) Info(format string, args ...interface{}) {
+	sendLog(o.manager, o.field, o.name, o.err, common.Info, format, args...)
+}
+
+func (o *boundField) Named(name string) FieldLogger {
+	return &boundField{err: o.err, field: o.field, manager: o.manager, name: name}
+}
+
+func (o *boundField) Warn(format string, args ...interface{}) {
+	sendLog(o.manager, o.field, o.name, o.err, common.Warn, format, args...)
+}
+
+func sendLog(m Management, field Field, name string, err error, level common.Level, format string, args ...interface{}) {
 	var kv loggers.Kv
 
 	// Copy Key/Value pairs into log component.
@@ -71,5 +167,9 @@ func sendLog(field Field, level common.Level, format string, args ...interface{}
 	}
 
 	// Send to executor by manager dispatcher.
-	Manager.Logger().Push(kv, level, format, args...)
+	if err != nil {
+		m.Logger().PushErr(name, kv, err, level, format, args...)
+		return
+	}
+	m.Logger().PushNamed(name, kv, level, format, args...)
 }
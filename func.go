@@ -17,8 +17,21 @@ package log
 
 import (
 	"github.com/fuyibing/log/v5/common"
+	"github.com/fuyibing/log/v5/loggers"
 )
 
+// AddHook
+// register hook of global Manager, called with logs of levels before
+// published, all levels if not specified. Span logs are included as they
+// are sent to logger too. Returned function remove hook.
+//
+//   remove := log.AddHook([]common.Level{common.Error, common.Fatal}, func(v loggers.Log) {
+//       errorCounter.Inc()
+//   }, loggers.HookAsync(1024))
+func AddHook(levels []common.Level, hook loggers.Hook, options ...loggers.HookOption) (remove func()) {
+	return Manager.Logger().AddHook(levels, hook, options...)
+}
+
 // Debug
 // send DEBUG level log to executor.
 func Debug(format string, args ...interface{}) {
@@ -0,0 +1,47 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-03-07
+
+package loggers
+
+import (
+	"github.com/fuyibing/log/v5/common"
+	"path/filepath"
+	"time"
+)
+
+// NewBucket
+// return bucket for logger executor. Lock-free ring used if configured,
+// otherwise overflow policy applied when bucket is full. Items spilled to
+// disk if enabled and ring / drop-newest bucket is full.
+func NewBucket(name string, operator OperatorManager) (bucket common.Bucket) {
+	config := operator.Config()
+
+	if config.GetBucketType() == common.BucketRing {
+		bucket = common.NewRingBucket(config.GetBucketCapacity())
+	} else {
+		bucket = common.NewBucketWithPolicy(
+			config.GetBucketCapacity(),
+			config.GetOverflowPolicy(),
+			time.Duration(config.GetOverflowTimeout())*time.Millisecond,
+		)
+	}
+
+	// Segments stored in sub directory
+	// named by instance and executor.
+	if spill := config.GetBucketSpill(); spill.GetEnable() {
+		bucket = common.NewDiskBucket(filepath.Join(config.GetInstance(), name), bucket, spill, NewCodec())
+	}
+	return
+}
@@ -0,0 +1,125 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-03-20
+
+package loggers
+
+import (
+	"path"
+	"reflect"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	callerDepth = 16
+)
+
+var (
+	// Resolved frames
+	// of program counter.
+	callerFrames sync.Map
+
+	// Module path,
+	// eg. github.com/fuyibing/log/v5.
+	callerModule = strings.TrimSuffix(reflect.TypeOf(operator{}).PkgPath(), "/loggers")
+)
+
+type (
+	// Caller
+	// location of log statement.
+	Caller struct {
+		File     string
+		Function string
+		Line     int
+
+		internal bool
+		pkg      string
+	}
+)
+
+// NewCaller
+// return location of first caller outside this module, then skip more
+// frames specified by skip, used by wrappers of this package. Nil returned
+// if not found. Returned caller is shared, don't modify it.
+func NewCaller(skip int) *Caller {
+	var (
+		found bool
+		pcs   [callerDepth]uintptr
+	)
+
+	for _, pc := range pcs[:runtime.Callers(2, pcs[:])] {
+		for _, c := range callerFramesOf(pc) {
+			if found = found || !c.internal; found {
+				if skip == 0 {
+					return c
+				}
+				skip--
+			}
+		}
+	}
+	return nil
+}
+
+// Package
+// return package path of caller, eg. github.com/app/orders.
+func (o *Caller) Package() string { return o.pkg }
+
+// String
+// return base name of file and line number, eg. order.go:123.
+func (o *Caller) String() string { return path.Base(o.File) + ":" + strconv.Itoa(o.Line) }
+
+// callerFramesOf
+// return frames of program counter, more than one if functions inlined.
+// Resolved once for each program counter.
+func callerFramesOf(pc uintptr) []*Caller {
+	if v, ok := callerFrames.Load(pc); ok {
+		return v.([]*Caller)
+	}
+
+	var (
+		frames = runtime.CallersFrames([]uintptr{pc})
+		list   = make([]*Caller, 0, 1)
+	)
+	for {
+		frame, more := frames.Next()
+		list = append(list, newCaller(frame.File, frame.Function, frame.Line))
+		if !more {
+			break
+		}
+	}
+
+	callerFrames.Store(pc, list)
+	return list
+}
+
+func newCaller(file, function string, line int) *Caller {
+	c := &Caller{File: file, Function: function, Line: line, pkg: packageOf(function)}
+	c.internal = c.pkg == callerModule || strings.HasPrefix(c.pkg, callerModule+"/")
+	return c
+}
+
+// packageOf
+// return package path of function name.
+//
+//   github.com/app/orders.(*Service).Create -> github.com/app/orders
+func packageOf(fn string) string {
+	i := strings.LastIndex(fn, "/") + 1
+	if j := strings.Index(fn[i:], "."); j >= 0 {
+		return fn[:i+j]
+	}
+	return fn
+}
@@ -0,0 +1,56 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-03-20
+
+package loggers
+
+import (
+	"github.com/fuyibing/log/v5/common"
+	"github.com/fuyibing/log/v5/configurer"
+	"github.com/fuyibing/util/v8/process"
+	"testing"
+)
+
+// discard
+// executor which drop published logs.
+type discard struct{}
+
+func (discard) Processor() process.Processor { return nil }
+func (discard) Publish(_ ...Log) error       { return nil }
+func (discard) SetFormatter(_ Formatter)     {}
+
+func BenchmarkCallerOff(b *testing.B) { benchmarkCaller(b, false, common.Info) }
+func BenchmarkCallerOn(b *testing.B)  { benchmarkCaller(b, true, common.Info) }
+
+// BenchmarkCallerDisabled
+// caller capture enabled, but level disabled.
+func BenchmarkCallerDisabled(b *testing.B) { benchmarkCaller(b, true, common.Debug) }
+
+func benchmarkCaller(b *testing.B, caller bool, level common.Level) {
+	config := configurer.New()
+	config.Setter().
+		SetLoggerCaller(caller).
+		SetLoggerLevel(common.Info)
+
+	o := NewOperator(config)
+	o.SetExecutor(discard{})
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			o.Push(nil, level, "message %d", 1)
+		}
+	})
+}
@@ -0,0 +1,78 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-03-07
+
+package loggers
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/fuyibing/log/v5/common"
+	"time"
+)
+
+type (
+	codec struct{}
+
+	codecLog struct {
+		Caller  *Caller            `json:"caller,omitempty"`
+		Kv      Kv                 `json:"kv,omitempty"`
+		Level   common.Level       `json:"level"`
+		SpanId  string             `json:"span_id,omitempty"`
+		Stack   bool               `json:"stack,omitempty"`
+		Stacks  []common.StackItem `json:"stacks,omitempty"`
+		Text    string             `json:"text"`
+		Time    time.Time          `json:"time"`
+		TraceId string             `json:"trace_id,omitempty"`
+	}
+)
+
+// NewCodec
+// return codec for Log component, used when log spilled to disk.
+func NewCodec() common.Codec { return &codec{} }
+
+func (o *codec) Decode(data []byte) (item interface{}, err error) {
+	v := &codecLog{}
+	if err = json.Unmarshal(data, v); err != nil {
+		return
+	}
+
+	x := &log{
+		kv: v.Kv, level: v.Level,
+		spanId: v.SpanId, traceId: v.TraceId,
+		text: v.Text, time: v.Time,
+	}
+	if v.Caller != nil {
+		x.caller = newCaller(v.Caller.File, v.Caller.Function, v.Caller.Line)
+	}
+	if v.Stack {
+		x.stack = common.NewLazyStackFromItems(v.Stacks)
+	}
+	item = x
+	return
+}
+
+func (o *codec) Encode(item interface{}) (data []byte, err error) {
+	v, ok := item.(Log)
+	if !ok {
+		return nil, fmt.Errorf("unsupported codec item: %T", item)
+	}
+
+	return json.Marshal(&codecLog{
+		Caller: v.Caller(), Kv: v.Kv(), Level: v.Level(),
+		SpanId: v.SpanId(), TraceId: v.TraceId(),
+		Stack: v.Stack(), Stacks: v.Stacks(),
+		Text: v.Text(), Time: v.Time(),
+	})
+}
@@ -0,0 +1,106 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-03-21
+
+package loggers
+
+import (
+	"fmt"
+	"github.com/fuyibing/log/v5/common"
+	"reflect"
+)
+
+const (
+	// ErrorChainKey
+	// key of cause chain in Kv.
+	ErrorChainKey = "error.chain"
+
+	errorChainDepth = 32
+)
+
+type (
+	// ErrorCause
+	// type and message of an error in cause chain.
+	ErrorCause struct {
+		Message string `json:"message"`
+		Type    string `json:"type"`
+	}
+)
+
+// ErrorChain
+// return causes of error, walked by Unwrap() error and Unwrap() []error
+// (eg. errors.Join) in depth first order, at most 32 causes.
+func ErrorChain(err error) []ErrorCause {
+	list := make([]ErrorCause, 0)
+	errorWalk(err, func(e error) {
+		list = append(list, ErrorCause{Message: e.Error(), Type: fmt.Sprintf("%T", e)})
+	})
+	return list
+}
+
+// ErrorStack
+// return stack of innermost cause which has StackTrace() method returns
+// slice of program counters, eg. github.com/pkg/errors. Nil returned if
+// not found.
+func ErrorStack(err error) (stack *common.LazyStack) {
+	errorWalk(err, func(e error) {
+		m := reflect.ValueOf(e).MethodByName("StackTrace")
+		if !m.IsValid() || m.Type().NumIn() != 0 || m.Type().NumOut() != 1 {
+			return
+		}
+
+		out := m.Call(nil)[0]
+		if out.Kind() != reflect.Slice || out.Type().Elem().Kind() != reflect.Uintptr || out.Len() == 0 {
+			return
+		}
+
+		pcs := make([]uintptr, out.Len())
+		for i := range pcs {
+			pcs[i] = uintptr(out.Index(i).Uint())
+		}
+		stack = common.NewLazyStackFromCallers(pcs)
+	})
+	return
+}
+
+// String
+// return type and message, eg. *fs.PathError: open x: no such file.
+func (o ErrorCause) String() string { return o.Type + ": " + o.Message }
+
+// errorWalk
+// call fn with each error of chain, stop if max depth reached.
+func errorWalk(err error, fn func(e error)) {
+	var (
+		depth int
+		walk  func(e error)
+	)
+
+	walk = func(e error) {
+		if e == nil || depth >= errorChainDepth {
+			return
+		}
+		depth++
+		fn(e)
+
+		switch x := e.(type) {
+		case interface{ Unwrap() []error }:
+			for _, c := range x.Unwrap() {
+				walk(c)
+			}
+		case interface{ Unwrap() error }:
+			walk(x.Unwrap())
+		}
+	}
+	walk(err)
+}
@@ -0,0 +1,162 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-03-24
+
+package loggers
+
+import (
+	"github.com/fuyibing/log/v5/common"
+	"sync"
+	"sync/atomic"
+)
+
+type (
+	// Hook
+	// called with log of selected levels before published to bucket, log
+	// is shared with executor, don't modify it.
+	Hook func(v Log)
+
+	// HookOption
+	// used to customize hook added by AddHook.
+	HookOption func(o *HookOptions)
+
+	// HookOptions
+	// options of hook added by AddHook.
+	HookOptions struct {
+		// Queue capacity of async hook, called synchronously if zero.
+		Capacity int
+	}
+
+	hook struct {
+		dropped *int64
+		fn      Hook
+		levels  int32
+		queue   chan Log
+		quit    chan struct{}
+	}
+
+	hooks struct {
+		dropped int64
+		list    atomic.Value
+		mu      sync.Mutex
+	}
+)
+
+// HookAsync
+// call hook in background goroutine, logs are queued with capacity and
+// dropped if queue is full, so slow hook never blocks callers. Dropped
+// count reported by HookDropped of operator.
+func HookAsync(capacity int) HookOption {
+	return func(o *HookOptions) {
+		if capacity < 1 {
+			capacity = 1
+		}
+		o.Capacity = capacity
+	}
+}
+
+// /////////////////////////////////////////////////////////////////////////////
+// Access methods
+// /////////////////////////////////////////////////////////////////////////////
+
+// add
+// register hook of levels, all levels if not specified. Returned function
+// remove hook and stop goroutine of async hook.
+func (o *hooks) add(levels []common.Level, fn Hook, options ...HookOption) (remove func()) {
+	var opts HookOptions
+	for _, option := range options {
+		option(&opts)
+	}
+
+	h := &hook{dropped: &o.dropped, fn: fn}
+	for _, l := range levels {
+		h.levels |= 1 << l.Upper().Int()
+	}
+	if opts.Capacity > 0 {
+		h.queue = make(chan Log, opts.Capacity)
+		h.quit = make(chan struct{})
+		go h.listen()
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	// Copy on write,
+	// read without lock by fire.
+	list, _ := o.list.Load().([]*hook)
+	o.list.Store(append(append([]*hook{}, list...), h))
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			o.mu.Lock()
+			defer o.mu.Unlock()
+
+			list, _ := o.list.Load().([]*hook)
+			next := make([]*hook, 0, len(list))
+			for _, x := range list {
+				if x != h {
+					next = append(next, x)
+				}
+			}
+			o.list.Store(next)
+
+			if h.quit != nil {
+				close(h.quit)
+			}
+		})
+	}
+}
+
+// fire
+// call hooks which level of log selected.
+func (o *hooks) fire(v Log) {
+	list, _ := o.list.Load().([]*hook)
+	for _, h := range list {
+		if h.levels != 0 && h.levels&(1<<v.Level().Int()) == 0 {
+			continue
+		}
+		if h.queue == nil {
+			h.call(v)
+			continue
+		}
+		select {
+		case h.queue <- v:
+		default:
+			atomic.AddInt64(h.dropped, 1)
+		}
+	}
+}
+
+// call
+// run hook with panic guard.
+func (o *hook) call(v Log) {
+	defer func() {
+		if r := recover(); r != nil {
+			common.InternalInfo("<loggers.hook> panic: %v", r)
+		}
+	}()
+	o.fn(v)
+}
+
+func (o *hook) listen() {
+	for {
+		select {
+		case v := <-o.queue:
+			o.call(v)
+		case <-o.quit:
+			return
+		}
+	}
+}
@@ -0,0 +1,152 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-03-25
+
+package loggers
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/fuyibing/log/v5/common"
+	"sort"
+	"time"
+)
+
+var (
+	// Field names of record,
+	// conflicted keys of flat fields prefixed.
+	jsonReserved = map[string]bool{
+		"caller": true, "fields": true, "level": true, "msg": true,
+		"span_id": true, "stack": true, "time": true, "trace_id": true,
+	}
+)
+
+type (
+	// JsonLog
+	// record of log in json lines format, field names are stable.
+	//
+	//   {"time":"2023-03-01T09:10:11.12346+08:00","level":"INFO","msg":"message","fields":{"key":"value"}}
+	JsonLog struct {
+		Time    string      `json:"time"`
+		Level   string      `json:"level"`
+		Msg     string      `json:"msg"`
+		Caller  string      `json:"caller,omitempty"`
+		TraceId string      `json:"trace_id,omitempty"`
+		SpanId  string      `json:"span_id,omitempty"`
+		Fields  Kv          `json:"fields,omitempty"`
+		Stack   []JsonStack `json:"stack,omitempty"`
+	}
+
+	// JsonStack
+	// frame of stack in json lines format, internal frames excluded.
+	JsonStack struct {
+		Call string `json:"call"`
+		File string `json:"file"`
+		Line int    `json:"line"`
+	}
+)
+
+// FlattenFields
+// merge fields into record m. Key conflicted with reserved field name is
+// prefixed with "fields." repeatedly until not used by record or other
+// fields, so no value is overwritten.
+func FlattenFields(m map[string]interface{}, fields Kv, reserved map[string]bool) {
+	conflicts := make([]string, 0)
+	for k, v := range fields {
+		if reserved[k] {
+			conflicts = append(conflicts, k)
+			continue
+		}
+		m[k] = v
+	}
+
+	sort.Strings(conflicts)
+	for _, k := range conflicts {
+		key := "fields." + k
+		for {
+			if _, ok := m[key]; !ok {
+				break
+			}
+			key = "fields." + key
+		}
+		m[key] = fields[k]
+	}
+}
+
+// NewJsonLog
+// return json record of log.
+func NewJsonLog(v Log) *JsonLog {
+	x := &JsonLog{
+		Time:    v.Time().Format(time.RFC3339Nano),
+		Level:   string(v.Level()),
+		Msg:     v.Text(),
+		TraceId: v.TraceId(),
+		SpanId:  v.SpanId(),
+		Fields:  v.Kv(),
+	}
+
+	if c := v.Caller(); c != nil {
+		x.Caller = c.String()
+	}
+
+	if v.Stack() {
+		for _, item := range v.Stacks() {
+			if !item.Internal {
+				x.Stack = append(x.Stack, JsonStack{Call: item.Call, File: item.File, Line: item.Line})
+			}
+		}
+	}
+	return x
+}
+
+// Marshal
+// return json bytes, fields are nested under fields, or merged into record
+// by FlattenFields if fields is flat. Values can't be encoded are formatted
+// as string.
+func (o *JsonLog) Marshal(fields common.FileFields) ([]byte, error) {
+	buf, err := o.marshal(fields)
+	if err != nil && len(o.Fields) > 0 {
+		x := *o
+		x.Fields = Kv{}
+		for k, v := range o.Fields {
+			x.Fields[k] = fmt.Sprintf("%v", v)
+		}
+		return x.marshal(fields)
+	}
+	return buf, err
+}
+
+func (o *JsonLog) marshal(fields common.FileFields) ([]byte, error) {
+	if fields != common.FileFieldsFlat || len(o.Fields) == 0 {
+		return json.Marshal(o)
+	}
+
+	m := map[string]interface{}{
+		"level": o.Level,
+		"msg":   o.Msg,
+		"time":  o.Time,
+	}
+	if o.Caller != "" {
+		m["caller"] = o.Caller
+	}
+	if o.TraceId != "" {
+		m["trace_id"], m["span_id"] = o.TraceId, o.SpanId
+	}
+	if len(o.Stack) > 0 {
+		m["stack"] = o.Stack
+	}
+
+	FlattenFields(m, o.Fields, jsonReserved)
+	return json.Marshal(m)
+}
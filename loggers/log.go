@@ -25,22 +25,48 @@ type (
 	// Log
 	// component for logger, stored with mixed.
 	Log interface {
+		// Caller
+		// return location of log statement, nil if not captured.
+		Caller() *Caller
+
 		Kv() Kv
 		Level() common.Level
+		SetCaller(c *Caller) Log
 		SetKv(s Kv) Log
+
+		// SetSpan
+		// bind log to span, ids are hex strings.
+		SetSpan(traceId, spanId string) Log
+
+		SetStack(s *common.LazyStack) Log
+		SetText(s string) Log
+
+		// SpanId
+		// return span id, empty if log not sent by span.
+		SpanId() string
+
 		Stack() bool
+
+		// Stacks
+		// return frames of stack, resolved on first call.
 		Stacks() []common.StackItem
 		Text() string
 		Time() time.Time
+
+		// TraceId
+		// return trace id, empty if log not sent by span.
+		TraceId() string
 	}
 
 	log struct {
-		kv     Kv
-		level  common.Level
-		stack  bool
-		stacks []common.StackItem
-		text   string
-		time   time.Time
+		caller  *Caller
+		kv      Kv
+		level   common.Level
+		spanId  string
+		stack   *common.LazyStack
+		text    string
+		time    time.Time
+		traceId string
 	}
 )
 
@@ -55,12 +81,19 @@ func NewLog(level common.Level, format string, args ...interface{}) Log {
 // Interface methods
 // /////////////////////////////////////////////////////////////////////////////
 
-func (o *log) Kv() Kv                     { return o.kv }
-func (o *log) Level() common.Level        { return o.level }
-func (o *log) Stack() bool                { return o.stack }
-func (o *log) Stacks() []common.StackItem { return o.stacks }
-func (o *log) Text() string               { return o.text }
-func (o *log) Time() time.Time            { return o.time }
+func (o *log) Caller() *Caller     { return o.caller }
+func (o *log) Kv() Kv              { return o.kv }
+func (o *log) Level() common.Level { return o.level }
+func (o *log) SpanId() string      { return o.spanId }
+func (o *log) Stack() bool         { return o.stack != nil }
+func (o *log) Text() string        { return o.text }
+func (o *log) Time() time.Time     { return o.time }
+func (o *log) TraceId() string     { return o.traceId }
+
+func (o *log) SetCaller(c *Caller) Log          { o.caller = c; return o }
+func (o *log) SetSpan(t, s string) Log          { o.traceId, o.spanId = t, s; return o }
+func (o *log) SetStack(s *common.LazyStack) Log { o.stack = s; return o }
+func (o *log) SetText(s string) Log             { o.text = s; return o }
 
 func (o *log) SetKv(s Kv) Log {
 	if o.kv == nil {
@@ -71,13 +104,15 @@ func (o *log) SetKv(s Kv) Log {
 	return o
 }
 
+func (o *log) Stacks() []common.StackItem {
+	if o.stack == nil {
+		return nil
+	}
+	return o.stack.Items()
+}
+
 // /////////////////////////////////////////////////////////////////////////////
 // Access and constructor
 // /////////////////////////////////////////////////////////////////////////////
 
-func (o *log) init() *log {
-	if o.stack = o.level == common.Fatal; o.stack {
-		o.stacks = common.Backstack().Items
-	}
-	return o
-}
+func (o *log) init() *log { return o }
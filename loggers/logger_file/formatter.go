@@ -23,7 +23,12 @@ import (
 
 type formatter struct{}
 
-func (o *formatter) Byte(_ ...loggers.Log) ([]byte, error) { return nil, nil }
+// Byte
+// 转成字节.
+func (o *formatter) Byte(vs ...loggers.Log) ([]byte, error) {
+	text, err := o.String(vs...)
+	return []byte(text), err
+}
 
 // String
 // 转成字符串.
@@ -49,6 +54,11 @@ func (o *formatter) format(v loggers.Log) (text string) {
 		v.Level(),
 	)
 
+	// 调用位置.
+	if c := v.Caller(); c != nil {
+		text += fmt.Sprintf("[%s]", c.String())
+	}
+
 	// 键值参数.
 	if kv := v.Kv(); len(kv) > 0 {
 		text += fmt.Sprintf(" %s", kv.String())
@@ -0,0 +1,57 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-03-25
+
+package logger_file
+
+import (
+	"bytes"
+	"github.com/fuyibing/log/v5/configurer"
+	"github.com/fuyibing/log/v5/loggers"
+)
+
+// jsonFormatter
+// 每条日志输出为一行 JSON.
+type jsonFormatter struct {
+	config configurer.ConfigLoggerFile
+}
+
+// Byte
+// 转成 JSON Lines.
+func (o *jsonFormatter) Byte(vs ...loggers.Log) ([]byte, error) {
+	var (
+		buf    bytes.Buffer
+		fields = o.config.GetFileLogger().GetFields()
+	)
+	for i, v := range vs {
+		body, err := loggers.NewJsonLog(v).Marshal(fields)
+		if err != nil {
+			return nil, err
+		}
+		if i > 0 {
+			buf.WriteByte('\n')
+		}
+		buf.Write(body)
+	}
+	return buf.Bytes(), nil
+}
+
+// String
+// 转成字符串.
+func (o *jsonFormatter) String(vs ...loggers.Log) (string, error) {
+	body, err := o.Byte(vs...)
+	return string(body), err
+}
+
+func (o *jsonFormatter) init() *jsonFormatter { return o }
@@ -0,0 +1,142 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-03-15
+
+// Package logger_memory
+// 记录到内存中, 用于单元测试断言.
+package logger_memory
+
+import (
+	"context"
+	"github.com/fuyibing/log/v5/common"
+	"github.com/fuyibing/log/v5/loggers"
+	"github.com/fuyibing/util/v8/process"
+	"strings"
+	"sync"
+)
+
+type (
+	// Executor
+	// record published logs synchronously, expose query methods.
+	//
+	//   ex := logger_memory.New()
+	//   log.Manager.Logger().SetExecutor(ex)
+	//
+	//   // ... call handler
+	//
+	//   ex.FindByLevel(common.Error)
+	Executor interface {
+		loggers.Executor
+
+		// FindByLevel
+		// return recorded logs of specified level.
+		FindByLevel(level common.Level) []loggers.Log
+
+		// FindByText
+		// return recorded logs whose text contains specified string.
+		FindByText(s string) []loggers.Log
+
+		// Logs
+		// return all recorded logs in published order.
+		Logs() []loggers.Log
+
+		// Reset
+		// clear recorded logs.
+		Reset()
+	}
+
+	executor struct {
+		sync.RWMutex
+
+		formatter loggers.Formatter
+		logs      []loggers.Log
+		name      string
+		processor process.Processor
+	}
+)
+
+func New() Executor { return (&executor{}).init() }
+
+// /////////////////////////////////////////////////////////////////////////////
+// Interface methods
+// /////////////////////////////////////////////////////////////////////////////
+
+func (o *executor) FindByLevel(level common.Level) []loggers.Log {
+	return o.find(func(v loggers.Log) bool { return v.Level() == level })
+}
+
+func (o *executor) FindByText(s string) []loggers.Log {
+	return o.find(func(v loggers.Log) bool { return strings.Contains(v.Text(), s) })
+}
+
+func (o *executor) Logs() []loggers.Log {
+	return o.find(func(_ loggers.Log) bool { return true })
+}
+
+func (o *executor) Processor() process.Processor     { return o.processor }
+func (o *executor) SetFormatter(v loggers.Formatter) { o.formatter = v }
+
+func (o *executor) Publish(logs ...loggers.Log) error {
+	o.Lock()
+	defer o.Unlock()
+
+	o.logs = append(o.logs, logs...)
+	return nil
+}
+
+func (o *executor) Reset() {
+	o.Lock()
+	defer o.Unlock()
+
+	o.logs = nil
+}
+
+// /////////////////////////////////////////////////////////////////////////////
+// Event methods
+// /////////////////////////////////////////////////////////////////////////////
+
+func (o *executor) onCall(ctx context.Context) (ignored bool) {
+	<-ctx.Done()
+	return
+}
+
+func (o *executor) onPanic(_ context.Context, v interface{}) {
+	common.InternalFatal("<%s> fatal: %v", o.name, v)
+}
+
+// /////////////////////////////////////////////////////////////////////////////
+// Access methods
+// /////////////////////////////////////////////////////////////////////////////
+
+func (o *executor) find(match func(v loggers.Log) bool) []loggers.Log {
+	o.RLock()
+	defer o.RUnlock()
+
+	list := make([]loggers.Log, 0)
+	for _, v := range o.logs {
+		if match(v) {
+			list = append(list, v)
+		}
+	}
+	return list
+}
+
+func (o *executor) init() *executor {
+	o.name = "logger.memory"
+	o.processor = process.New(o.name).
+		Callback(o.onCall).
+		Panic(o.onPanic)
+
+	return o
+}
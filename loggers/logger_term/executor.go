@@ -29,13 +29,20 @@ import (
 
 type executor struct {
 	bucket     common.Bucket
+	config     configurer.Configuration
 	formatter  loggers.Formatter
 	name       string
-	processor  process.Processor
 	processing int32
+	processor  process.Processor
 }
 
-func New() loggers.Executor { return (&executor{}).init() }
+func New() loggers.Executor { return NewWith(loggers.Operator) }
+
+// NewWith
+// create and return executor bound to specified operator.
+func NewWith(operator loggers.OperatorManager) loggers.Executor {
+	return (&executor{config: operator.Config()}).init()
+}
 
 // /////////////////////////////////////////////////////////////////////////////
 // Interface methods
@@ -73,7 +80,7 @@ func (o *executor) onPanic(_ context.Context, v interface{}) {
 // /////////////////////////////////////////////////////////////////////////////
 
 func (o *executor) init() *executor {
-	o.bucket = common.NewBucket(configurer.Config.GetBucketCapacity())
+	o.bucket = common.NewBucket(o.config.GetBucketCapacity())
 	o.formatter = (&formatter{}).init()
 	o.name = "logger.term"
 	o.processor = process.New(o.name).
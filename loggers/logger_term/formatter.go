@@ -68,6 +68,11 @@ func (o *formatter) format(v loggers.Log) (text string) {
 		text += fmt.Sprintf("[%5s]", v.Level())
 	}
 
+	// 调用位置.
+	if c := v.Caller(); c != nil {
+		text += fmt.Sprintf("[%s]", c.String())
+	}
+
 	// 键值参数.
 	if kv := v.Kv(); len(kv) > 0 {
 		text += fmt.Sprintf(" %s", kv.String())
@@ -19,6 +19,7 @@ import (
 	"github.com/fuyibing/log/v5/common"
 	"github.com/fuyibing/log/v5/configurer"
 	"sync"
+	"sync/atomic"
 )
 
 var Operator OperatorManager
@@ -27,32 +28,135 @@ type (
 	// OperatorManager
 	// for logger operations.
 	OperatorManager interface {
+		// AddHook
+		// register hook called with logs of levels, all levels if not
+		// specified. Returned function remove hook.
+		AddHook(levels []common.Level, hook Hook, options ...HookOption) (remove func())
+
+		// Config
+		// return configuration of operator.
+		Config() configurer.Configuration
+
 		// GetExecutor
 		// return logger executor.
 		GetExecutor() (executor Executor)
 
+		// HookDropped
+		// return total count of logs dropped by async hooks, queue of hook
+		// was full.
+		HookDropped() int64
+
 		// Push
 		// log component on to executor.
 		Push(kv Kv, level common.Level, format string, args ...interface{})
 
+		// PushNamed
+		// log component of named logger on to executor, level resolved by
+		// logger-levels.
+		PushNamed(name string, kv Kv, level common.Level, format string, args ...interface{})
+
+		// PushErr
+		// log component with cause chain of error on to executor, stack
+		// of error used if carried.
+		PushErr(name string, kv Kv, err error, level common.Level, format string, args ...interface{})
+
+		// PushSpan
+		// log component of span on to executor, error is optional.
+		PushSpan(traceId, spanId string, kv Kv, err error, level common.Level, format string, args ...interface{})
+
+		// Sampler
+		// return sampler of logs.
+		Sampler() *Sampler
+
 		// SetExecutor
 		// configure logger executor.
 		SetExecutor(executor Executor)
+
+		// Sweep
+		// publish suppressed counts of operator sampler and specified
+		// samplers (eg. span logs of tracer), interval ended only unless
+		// force is true.
+		Sweep(force bool, samplers ...*Sampler)
 	}
 
 	operator struct {
+		config   configurer.Configuration
 		executor Executor
+		hooks    hooks
+		mu       sync.RWMutex
 		name     string
+		sampler  *Sampler
+	}
+
+	// pushing
+	// source of log component.
+	pushing struct {
+		err             error
+		kv              Kv
+		name            string
+		spanId, traceId string
 	}
 )
 
+// NewOperator
+// create and return an independent logger operator with specified
+// configuration.
+func NewOperator(config configurer.Configuration) OperatorManager {
+	return (&operator{config: config}).init()
+}
+
 // /////////////////////////////////////////////////////////////////////////////
 // Interface methods
 // /////////////////////////////////////////////////////////////////////////////
 
-func (o *operator) GetExecutor() Executor                                 { return o.executor }
-func (o *operator) Push(k Kv, l common.Level, s string, a ...interface{}) { o.send(k, l, s, a...) }
-func (o *operator) SetExecutor(v Executor)                                { o.executor = v }
+func (o *operator) AddHook(levels []common.Level, hook Hook, options ...HookOption) func() {
+	return o.hooks.add(levels, hook, options...)
+}
+
+func (o *operator) Config() configurer.Configuration { return o.config }
+func (o *operator) HookDropped() int64               { return atomic.LoadInt64(&o.hooks.dropped) }
+func (o *operator) Sampler() *Sampler                { return o.sampler }
+
+func (o *operator) Push(k Kv, l common.Level, s string, a ...interface{}) {
+	o.send(pushing{kv: k}, l, s, a...)
+}
+
+func (o *operator) PushErr(name string, kv Kv, err error, level common.Level, format string, args ...interface{}) {
+	o.send(pushing{err: err, kv: kv, name: name}, level, format, args...)
+}
+
+func (o *operator) PushNamed(name string, kv Kv, level common.Level, format string, args ...interface{}) {
+	o.send(pushing{kv: kv, name: name}, level, format, args...)
+}
+
+func (o *operator) PushSpan(traceId, spanId string, kv Kv, err error, level common.Level, format string, args ...interface{}) {
+	o.send(pushing{err: err, kv: kv, spanId: spanId, traceId: traceId}, level, format, args...)
+}
+
+func (o *operator) GetExecutor() Executor {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	return o.executor
+}
+
+func (o *operator) SetExecutor(v Executor) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.executor = v
+}
+
+func (o *operator) Sweep(force bool, samplers ...*Sampler) {
+	executor := o.GetExecutor()
+	if executor == nil {
+		return
+	}
+
+	for _, s := range append([]*Sampler{o.sampler}, samplers...) {
+		for _, v := range s.Sweep(force) {
+			o.publish(executor, v)
+		}
+	}
+}
 
 // /////////////////////////////////////////////////////////////////////////////
 // Access and constructor
@@ -60,13 +164,40 @@ func (o *operator) SetExecutor(v Executor)                                { o.ex
 
 func (o *operator) init() *operator {
 	o.name = "loggers.operator"
+	o.sampler = NewSampler(o.config)
 	return o
 }
 
-func (o *operator) send(kv Kv, level common.Level, format string, args ...interface{}) {
+func (o *operator) send(p pushing, level common.Level, format string, args ...interface{}) {
+	executor := o.GetExecutor()
+	if executor == nil {
+		return
+	}
+
+	name := p.name
+
+	// Resolve package of caller
+	// if logger not named.
+	var caller *Caller
+	if name == "" && o.config.GetLoggerLevelsCaller() {
+		if caller = NewCaller(o.config.GetLoggerCallerSkip()); caller != nil {
+			name = caller.Package()
+		}
+	}
+
+	// Ignore
+	// if log level is greater than configured.
+	if !o.config.LevelEnabledFor(name, level) {
+		return
+	}
+
 	// Ignore
-	// if executor not specified or log level is greater than configured.
-	if o.executor == nil || !configurer.Config.LevelEnabled(level) {
+	// if limited by sampler, report suppressed count of previous interval.
+	passed, suppressed := o.sampler.Sample(level, format)
+	if suppressed > 0 {
+		o.publish(executor, NewSamplerSummary(level, format, suppressed))
+	}
+	if !passed {
 		return
 	}
 
@@ -76,15 +207,59 @@ func (o *operator) send(kv Kv, level common.Level, format string, args ...interf
 
 	// Copy
 	// key/value pair.
-	if kv != nil {
-		v.SetKv(kv)
+	if p.kv != nil {
+		v.SetKv(p.kv)
+	}
+
+	// Copy cause chain
+	// and stack of error.
+	if p.err != nil {
+		v.SetKv(Kv{ErrorChainKey: ErrorChain(p.err)})
+		if s := ErrorStack(p.err); s != nil {
+			v.SetStack(s)
+		}
+	}
+
+	// Bind span
+	// if sent by span logger.
+	if p.traceId != "" {
+		v.SetSpan(p.traceId, p.spanId)
+	}
+
+	// Capture stack,
+	// frames resolved when printed.
+	if !v.Stack() && o.config.StackEnabled(level) {
+		v.SetStack(common.NewLazyStack(0, common.StackDepth))
 	}
 
+	// Capture location,
+	// reuse if resolved for level.
+	if o.config.GetLoggerCaller() {
+		if caller == nil {
+			caller = NewCaller(o.config.GetLoggerCallerSkip())
+		}
+		v.SetCaller(caller)
+	}
+
+	o.publish(executor, v)
+}
+
+func (o *operator) publish(executor Executor, v Log) {
+	// Redact sensitive data
+	// before formatted.
+	if r := o.config.GetRedact(); r.Enabled() {
+		Redact(r, v)
+	}
+
+	// Call hooks
+	// before published.
+	o.hooks.fire(v)
+
 	// Call specified executor
 	// then push into it.
-	if err := o.executor.Publish(v); err != nil {
+	if err := executor.Publish(v); err != nil {
 		common.InternalInfo("<%s> publish: %v", o.name, err)
 	}
 }
 
-func init() { new(sync.Once).Do(func() { Operator = (&operator{}).init() }) }
+func init() { new(sync.Once).Do(func() { Operator = NewOperator(configurer.Config) }) }
@@ -0,0 +1,104 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-03-22
+
+package loggers
+
+import (
+	"fmt"
+	"github.com/fuyibing/log/v5/configurer"
+	"reflect"
+)
+
+// Redact
+// replace sensitive data of kv and text in log, called before formatted.
+func Redact(r configurer.Redact, v Log) {
+	if kv := v.Kv(); len(kv) > 0 {
+		kv.Redact(r)
+	}
+	if s, ok := r.Replace(v.Text()); ok {
+		v.SetText(s)
+	}
+}
+
+// Redact
+// replace sensitive data in place. Denied keys are masked, hashed or
+// removed by mode, nested maps and slices are copied before redacted, so
+// values shared with caller (eg. http.Header) are not modified.
+func (o Kv) Redact(r configurer.Redact) Kv {
+	for k, v := range o {
+		if nv, keep := redactField(r, k, v); keep {
+			o[k] = nv
+		} else {
+			delete(o, k)
+		}
+	}
+	return o
+}
+
+// redactField
+// return redacted value of key, false returned if key should be dropped.
+func redactField(r configurer.Redact, key string, v interface{}) (interface{}, bool) {
+	if r.MatchKey(key) {
+		if r.IsDrop() {
+			return nil, false
+		}
+		if s, ok := v.(string); ok {
+			return r.Value(s), true
+		}
+		return r.Value(fmt.Sprint(v)), true
+	}
+	return redactValue(r, v), true
+}
+
+// redactValue
+// return copy of value which sensitive data replaced by value rules.
+func redactValue(r configurer.Redact, v interface{}) interface{} {
+	switch x := v.(type) {
+	case nil, bool, int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64, float32, float64:
+		return v
+	case string:
+		s, _ := r.Replace(x)
+		return s
+	case ErrorCause:
+		x.Message, _ = r.Replace(x.Message)
+		return x
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Map:
+		if rv.Type().Key().Kind() != reflect.String {
+			return v
+		}
+		m := make(map[string]interface{}, rv.Len())
+		for it := rv.MapRange(); it.Next(); {
+			if nv, keep := redactField(r, it.Key().String(), it.Value().Interface()); keep {
+				m[it.Key().String()] = nv
+			}
+		}
+		return m
+
+	case reflect.Array, reflect.Slice:
+		if rv.Type().Elem().Kind() == reflect.Uint8 {
+			return v
+		}
+		list := make([]interface{}, rv.Len())
+		for i := range list {
+			list[i] = redactValue(r, rv.Index(i).Interface())
+		}
+		return list
+	}
+	return v
+}
@@ -0,0 +1,168 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-03-23
+
+package loggers
+
+import (
+	"github.com/fuyibing/log/v5/common"
+	"github.com/fuyibing/log/v5/configurer"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	// SamplerSuppressedKey
+	// key of suppressed count in Kv of summary log.
+	SamplerSuppressedKey = "sampling.suppressed"
+
+	// Keys counted at most,
+	// logs of new keys passed if exceeded.
+	samplerMaxKeys = 4096
+)
+
+type (
+	// Sampler
+	// limit logs of same level and format string, first initial logs
+	// passed in each interval, then 1 of every thereafter logs.
+	Sampler struct {
+		suppressed uint64
+
+		config configurer.ConfigLoggerSampling
+		keys   map[samplerKey]*samplerCounter
+		mu     sync.RWMutex
+	}
+
+	samplerCounter struct {
+		count, dropped uint64
+		reset          int64
+	}
+
+	samplerKey struct {
+		format string
+		level  common.Level
+	}
+)
+
+// NewSampler
+// create and return sampler, reads configuration on each call so changes
+// on reload take effect.
+func NewSampler(config configurer.ConfigLoggerSampling) *Sampler {
+	return &Sampler{config: config, keys: make(map[samplerKey]*samplerCounter)}
+}
+
+// NewSamplerSummary
+// return log which report count of suppressed logs of level and format.
+func NewSamplerSummary(level common.Level, format string, suppressed uint64) Log {
+	return NewLog(level, "%d duplicate logs suppressed: %s", suppressed, format).
+		SetKv(Kv{SamplerSuppressedKey: suppressed})
+}
+
+// Keys
+// return count of keys counted.
+func (o *Sampler) Keys() int {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	return len(o.keys)
+}
+
+// Sample
+// return true if log of level and format passed. Count of logs suppressed
+// in previous interval returned once when next interval started, caller
+// should report it.
+func (o *Sampler) Sample(level common.Level, format string) (passed bool, suppressed uint64) {
+	cfg := o.config.GetLoggerSampling()
+	if !cfg.GetEnable() {
+		return true, 0
+	}
+
+	c := o.counter(samplerKey{format: format, level: level})
+	if c == nil {
+		return true, 0
+	}
+
+	var (
+		n   uint64
+		now = time.Now().UnixNano()
+	)
+
+	// Start next interval
+	// by first caller which reached reset time.
+	if reset := atomic.LoadInt64(&c.reset); now >= reset {
+		if atomic.CompareAndSwapInt64(&c.reset, reset, now+int64(cfg.GetInterval())*int64(time.Millisecond)) {
+			atomic.StoreUint64(&c.count, 0)
+			suppressed = atomic.SwapUint64(&c.dropped, 0)
+		}
+	}
+
+	n = atomic.AddUint64(&c.count, 1)
+	initial, thereafter := uint64(cfg.GetInitial()), uint64(cfg.GetThereafter())
+	if n <= initial || (thereafter > 0 && (n-initial)%thereafter == 0) {
+		return true, suppressed
+	}
+
+	atomic.AddUint64(&c.dropped, 1)
+	atomic.AddUint64(&o.suppressed, 1)
+	return false, suppressed
+}
+
+// Suppressed
+// return total count of logs suppressed since created.
+func (o *Sampler) Suppressed() uint64 { return atomic.LoadUint64(&o.suppressed) }
+
+// Sweep
+// return summaries of counters which interval ended with suppressed logs,
+// all suppressed counts returned if force is true. Used to report counts
+// when logs of key stopped.
+func (o *Sampler) Sweep(force bool) (list []Log) {
+	now := time.Now().UnixNano()
+
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+
+	for key, c := range o.keys {
+		if !force && now < atomic.LoadInt64(&c.reset) {
+			continue
+		}
+		if n := atomic.SwapUint64(&c.dropped, 0); n > 0 {
+			list = append(list, NewSamplerSummary(key.level, key.format, n))
+		}
+	}
+	return
+}
+
+// counter
+// return counter of key, created if not exists. Nil returned if max keys
+// reached.
+func (o *Sampler) counter(key samplerKey) *samplerCounter {
+	o.mu.RLock()
+	c, ok := o.keys[key]
+	o.mu.RUnlock()
+	if ok {
+		return c
+	}
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if c, ok = o.keys[key]; !ok {
+		if len(o.keys) >= samplerMaxKeys {
+			return nil
+		}
+		c = &samplerCounter{}
+		o.keys[key] = c
+	}
+	return c
+}
@@ -17,12 +17,17 @@ package log
 
 import (
 	"context"
+	"fmt"
 	"github.com/fuyibing/log/v5/common"
 	"github.com/fuyibing/log/v5/configurer"
 	"github.com/fuyibing/log/v5/loggers"
 	"github.com/fuyibing/log/v5/tracers"
 	"github.com/fuyibing/util/v8/process"
+	"net/http"
+	"os"
+	"os/signal"
 	"sync"
+	"syscall"
 	"time"
 )
 
@@ -30,22 +35,71 @@ var (
 	Manager Management
 )
 
+const (
+	managerStartTimeout = time.Second
+	managerStopTimeout  = time.Second * 30
+	managerWaitInterval = time.Millisecond * 10
+
+	// Used if sampling interval
+	// is invalid, in milliseconds.
+	managerSweepInterval = 1000
+)
+
 type (
 	Management interface {
+		// AdminHandler
+		// return http handler which get and set logger level, show
+		// executor stats and flush buckets.
+		AdminHandler() http.Handler
+
 		// Config
 		// global configurations, readonly.
 		Config() configurer.Configuration
 
+		// Flush
+		// block until buckets of logger and tracer are drained and in-flight
+		// sends finished. Context error returned if deadline exceeded.
+		Flush(ctx context.Context) error
+
 		// Logger
 		// log operator.
 		Logger() loggers.OperatorManager
 
+		// NewSpan
+		// return a Span component bound to manager.
+		NewSpan(name string) tracers.Span
+
+		// NewSpanFromContext
+		// return a Span component bound to manager, based on specified
+		// context.Context.
+		NewSpanFromContext(ctx context.Context, name string) tracers.Span
+
+		// NewSpanFromRequest
+		// return a Span component bound to manager, based on http request.
+		NewSpanFromRequest(req *http.Request, name string) tracers.Span
+
+		// OnReload
+		// register handler called after config file reloaded.
+		OnReload(handler ReloadHandler)
+
+		// Reload
+		// read config file again and apply changes, same as config file
+		// modified or SIGHUP received if hot-reload enabled.
+		Reload() *ReloadEvent
+
 		// Tracer
 		// trace operator.
 		Tracer() tracers.OperatorManager
 
+		// Shutdown
+		// flush logs and spans then stop manager, respect deadline of
+		// context. ShutdownError returned if deadline exceeded or any log or
+		// span dropped.
+		Shutdown(ctx context.Context) error
+
 		// Start
-		// start boot manager as async mode.
+		// start boot manager as async mode. Configuration validated first,
+		// panic with configurer.ValidationError if invalid in strict mode.
 		Start(ctx context.Context)
 
 		// Stop
@@ -54,11 +108,38 @@ type (
 		Stop()
 	}
 
+	// ShutdownError
+	// returned by Shutdown, contains dropped count of logs and spans.
+	// Remained items in bucket are counted as dropped if deadline exceeded.
+	ShutdownError struct {
+		Err   error
+		Logs  int64
+		Spans int64
+	}
+
+	// ReloadEvent
+	// reported after config file reloaded, contains changed fields.
+	ReloadEvent struct {
+		Changes []configurer.Change
+		Err     error
+		Time    time.Time
+	}
+
+	// ReloadHandler
+	// called after config file reloaded.
+	ReloadHandler func(event *ReloadEvent)
+
 	manager struct {
+		sync.RWMutex
+
+		admin     *admin
+		adminOnce sync.Once
 		config    configurer.Configuration
+		handlers  []ReloadHandler
 		logger    loggers.OperatorManager
 		name      string
 		processor process.Processor
+		reloads   chan chan *ReloadEvent
 		tracer    tracers.OperatorManager
 	}
 )
@@ -67,22 +148,132 @@ type (
 // Interface methods
 // /////////////////////////////////////////////////////////////////////////////
 
-func (o *manager) Config() configurer.Configuration { return o.config }
-func (o *manager) Logger() loggers.OperatorManager  { return o.logger }
-func (o *manager) Tracer() tracers.OperatorManager  { return o.tracer }
-func (o *manager) Start(ctx context.Context)        { o.start(ctx) }
-func (o *manager) Stop()                            { o.stop() }
+func (o *manager) Config() configurer.Configuration   { return o.config }
+func (o *manager) Flush(ctx context.Context) error    { return o.flush(ctx) }
+func (o *manager) Logger() loggers.OperatorManager    { return o.logger }
+func (o *manager) NewSpan(name string) tracers.Span   { return o.tracer.NewSpan(name) }
+func (o *manager) Shutdown(ctx context.Context) error { return o.shutdown(ctx) }
+func (o *manager) Tracer() tracers.OperatorManager    { return o.tracer }
+func (o *manager) Start(ctx context.Context)          { o.start(ctx) }
+func (o *manager) Stop()                              { o.stop() }
+
+func (o *manager) NewSpanFromContext(ctx context.Context, name string) tracers.Span {
+	return o.tracer.NewSpanFromContext(ctx, name)
+}
+
+func (o *manager) AdminHandler() http.Handler {
+	o.adminOnce.Do(func() { o.admin = &admin{manager: o, reverts: make(map[string]*adminRevert)} })
+	return o.admin
+}
+
+func (o *manager) OnReload(handler ReloadHandler) {
+	o.Lock()
+	defer o.Unlock()
+	o.handlers = append(o.handlers, handler)
+}
+
+func (o *manager) Reload() *ReloadEvent {
+	// Reload in callback of processor
+	// which swap executors if changed.
+	if o.processor.Healthy() {
+		reply := make(chan *ReloadEvent, 1)
+		select {
+		case o.reloads <- reply:
+			return <-reply
+		case <-time.After(managerStartTimeout):
+		}
+	}
+
+	event := o.reload()
+	o.notify(event)
+	return event
+}
+
+func (o *manager) NewSpanFromRequest(req *http.Request, name string) tracers.Span {
+	return o.tracer.NewSpanFromRequest(req, name)
+}
+
+func (e *ShutdownError) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("shutdown: logs=%d, spans=%d dropped, %v", e.Logs, e.Spans, e.Err)
+	}
+	return fmt.Sprintf("shutdown: logs=%d, spans=%d dropped", e.Logs, e.Spans)
+}
+
+func (e *ShutdownError) Unwrap() error { return e.Err }
 
 // /////////////////////////////////////////////////////////////////////////////
 // Event methods
 // /////////////////////////////////////////////////////////////////////////////
 
+func (o *manager) onBeforeDeadLetter(_ context.Context) (ignored bool) {
+	// Add dead letter executor as child process which configured by user
+	// code.
+	if ex := o.tracer.GetDeadLetter(); ex != nil {
+		if _, exists := o.processor.Get(ex.Processor().Name()); !exists {
+			o.processor.Add(ex.Processor())
+		}
+		return
+	}
+
+	// Add dead letter executor as child process which configured by config
+	// file, ignored if same as tracer exporter.
+	name := o.config.GetHttpRetry().GetDeadLetter()
+	if name == "" || name == o.config.GetTracerExporter() {
+		return
+	}
+	if call, ok := builtinTracers[name]; ok {
+		if ex := call(o.tracer); ex != nil {
+			common.InternalInfo(`<%s> dead letter executor [name="%s"]`,
+				o.name, ex.Processor().Name(),
+			)
+
+			o.tracer.SetDeadLetter(ex)
+			o.processor.Add(ex.Processor())
+		}
+	}
+	return
+}
+
+func (o *manager) onBeforeFallback(_ context.Context) (ignored bool) {
+	// Add fallback executor as child process which configured by user code.
+	if ex := o.tracer.GetFallback(); ex != nil {
+		if _, exists := o.processor.Get(ex.Processor().Name()); !exists {
+			o.processor.Add(ex.Processor())
+		}
+		return
+	}
+
+	// Add fallback executor as child process which configured by config
+	// file, ignored if same as tracer exporter. Reuse dead letter executor
+	// if names are same.
+	name := o.config.GetCircuitBreaker().GetFallback()
+	if name == "" || name == o.config.GetTracerExporter() {
+		return
+	}
+	if ex := o.tracer.GetDeadLetter(); ex != nil && name == o.config.GetHttpRetry().GetDeadLetter() {
+		o.tracer.SetFallback(ex)
+		return
+	}
+	if call, ok := builtinTracers[name]; ok {
+		if ex := call(o.tracer); ex != nil {
+			common.InternalInfo(`<%s> fallback executor [name="%s"]`,
+				o.name, ex.Processor().Name(),
+			)
+
+			o.tracer.SetFallback(ex)
+			o.processor.Add(ex.Processor())
+		}
+	}
+	return
+}
+
 func (o *manager) onBeforeLogger(_ context.Context) (ignored bool) {
 	// Add logger exporter as child process which configured by user code.
 	if ex := o.logger.GetExecutor(); ex != nil {
 		common.InternalInfo(`<%s> logger executor [name="%s"][level="%s"]`,
 			o.name, ex.Processor().Name(),
-			configurer.Config.GetLoggerLevel(),
+			o.config.GetLoggerLevel(),
 		)
 
 		if _, exists := o.processor.Get(ex.Processor().Name()); !exists {
@@ -92,11 +283,11 @@ func (o *manager) onBeforeLogger(_ context.Context) (ignored bool) {
 	}
 
 	// Add logger exporter as child process which configured by config file.
-	if call, ok := builtinLoggers[configurer.Config.GetLoggerExporter()]; ok {
-		if ex := call(); ex != nil {
+	if call, ok := builtinLoggers[o.config.GetLoggerExporter()]; ok {
+		if ex := call(o.logger); ex != nil {
 			common.InternalInfo(`<%s> logger executor [name="%s"][level="%s"]`,
 				o.name, ex.Processor().Name(),
-				configurer.Config.GetLoggerLevel(),
+				o.config.GetLoggerLevel(),
 			)
 
 			o.logger.SetExecutor(ex)
@@ -112,7 +303,7 @@ func (o *manager) onBeforeTracer(_ context.Context) (ignored bool) {
 	if ex := o.tracer.GetExecutor(); ex != nil {
 		common.InternalInfo(`<%s> tracer executor [name="%s"][topic="%s"]`,
 			o.name, ex.Processor().Name(),
-			configurer.Config.GetTracerTopic(),
+			o.config.GetTracerTopic(),
 		)
 
 		if _, exists := o.processor.Get(ex.Processor().Name()); !exists {
@@ -122,11 +313,11 @@ func (o *manager) onBeforeTracer(_ context.Context) (ignored bool) {
 	}
 
 	// Add tracer exporter as child process which configured by config file.
-	if call, ok := builtinTracers[configurer.Config.GetTracerExporter()]; ok {
-		if ex := call(); ex != nil {
+	if call, ok := builtinTracers[o.config.GetTracerExporter()]; ok {
+		if ex := call(o.tracer); ex != nil {
 			common.InternalInfo(`<%s> tracer executor [name="%s"][topic="%s"]`,
 				o.name, ex.Processor().Name(),
-				configurer.Config.GetTracerTopic(),
+				o.config.GetTracerTopic(),
 			)
 
 			o.tracer.SetExecutor(ex)
@@ -138,10 +329,61 @@ func (o *manager) onBeforeTracer(_ context.Context) (ignored bool) {
 }
 
 func (o *manager) onCall(ctx context.Context) (ignored bool) {
+	var (
+		hr      = o.config.GetHotReload()
+		signals = make(chan os.Signal, 1)
+		watch   <-chan time.Time
+	)
+
+	// Report suppressed counts
+	// of samplers per interval.
+	freq := o.config.GetLoggerSampling().GetInterval()
+	if freq <= 0 {
+		freq = managerSweepInterval
+	}
+	sweep := time.NewTicker(time.Duration(freq) * time.Millisecond)
+	defer sweep.Stop()
+
+	// Reload config file
+	// when SIGHUP received.
+	if hr.GetSignal() {
+		signal.Notify(signals, syscall.SIGHUP)
+		defer signal.Stop(signals)
+	}
+
+	// Reload config file
+	// when modified.
+	if hr.GetEnable() {
+		ti := time.NewTicker(time.Duration(hr.GetInterval()) * time.Millisecond)
+		defer ti.Stop()
+		watch = ti.C
+	}
+
+	apply := func() *ReloadEvent {
+		event := o.reload()
+		o.swap(ctx, event)
+		o.notify(event)
+		return event
+	}
+
 	for {
 		select {
 		case <-ctx.Done():
 			return
+		case reply := <-o.reloads:
+			reply <- apply()
+		case <-signals:
+			apply()
+		case <-watch:
+			if o.config.Modified() {
+				apply()
+			}
+		case <-sweep.C:
+			if n := o.config.GetLoggerSampling().GetInterval(); n > 0 && n != freq {
+				freq = n
+				sweep.Reset(time.Duration(freq) * time.Millisecond)
+			}
+			o.sweep(false)
 		}
 	}
 }
@@ -154,21 +396,150 @@ func (o *manager) onPanic(_ context.Context, v interface{}) {
 // Access and constructor
 // /////////////////////////////////////////////////////////////////////////////
 
-func (o *manager) init() *manager {
-	o.config = configurer.Config
-	o.logger = loggers.Operator
+// flushers
+// return async executors of logger and tracer, spans executors first, then
+// dead letter and fallback which may receive spans from tracer executor.
+func (o *manager) flushers() (logs, spans []common.Flusher) {
+	if f, ok := o.logger.GetExecutor().(common.Flusher); ok {
+		logs = append(logs, f)
+	}
+
+	seen := make(map[common.Flusher]bool)
+	for _, ex := range []tracers.Executor{o.tracer.GetExecutor(), o.tracer.GetDeadLetter(), o.tracer.GetFallback()} {
+		if f, ok := ex.(common.Flusher); ok && !seen[f] {
+			seen[f] = true
+			spans = append(spans, f)
+		}
+	}
+	return
+}
+
+func (o *manager) flush(ctx context.Context) error {
+	o.sweep(true)
+
+	logs, spans := o.flushers()
+	for _, f := range append(spans, logs...) {
+		if err := f.Flush(ctx); err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
+func (o *manager) init() *manager {
 	o.name = "manager"
+	o.reloads = make(chan chan *ReloadEvent)
 	o.processor = process.New(o.name).
-		Before(o.onBeforeLogger, o.onBeforeTracer).
+		Before(o.onBeforeLogger, o.onBeforeTracer, o.onBeforeDeadLetter, o.onBeforeFallback).
 		Callback(o.onCall).
 		Panic(o.onPanic)
-	o.tracer = tracers.Operator
 
 	return o
 }
 
+// notify
+// call registered handlers with reload event.
+func (o *manager) notify(event *ReloadEvent) {
+	o.RLock()
+	handlers := o.handlers
+	o.RUnlock()
+
+	for _, handler := range handlers {
+		handler(event)
+	}
+}
+
+// reload
+// config file and report changed fields.
+func (o *manager) reload() *ReloadEvent {
+	changes, err := o.config.Reload()
+	if err != nil {
+		common.InternalInfo("<%s> reload: %v", o.name, err)
+	}
+
+	for _, c := range changes {
+		if c.Applied {
+			common.InternalInfo(`<%s> reload: %s changed [old="%v"][new="%v"]`, o.name, c.Key, c.Old, c.New)
+		} else {
+			common.InternalInfo(`<%s> reload: %s changed, restart required`, o.name, c.Key)
+		}
+	}
+
+	return &ReloadEvent{Changes: changes, Err: err, Time: time.Now()}
+}
+
+// retire
+// stop child processor of swapped executor, unregistered after stopped so
+// remaining items are drained.
+func (o *manager) retire(p process.Processor) {
+	p.Stop()
+	go func() {
+		ti := time.NewTicker(managerWaitInterval)
+		defer ti.Stop()
+
+		for range ti.C {
+			if p.Stopped() {
+				// Name may be shared by dead letter
+				// or fallback executor.
+				if c, ok := o.processor.Get(p.Name()); ok && c == p {
+					o.processor.Del(p.Name())
+				}
+				return
+			}
+		}
+	}()
+}
+
+func (o *manager) shutdown(ctx context.Context) error {
+	var (
+		err         = o.flush(ctx)
+		logs, spans = o.flushers()
+		res         = &ShutdownError{Err: err}
+	)
+
+	// Send stop signal
+	// and wait until stopped or deadline exceeded.
+	o.processor.Stop()
+	if err == nil {
+		res.Err = o.wait(ctx)
+	}
+
+	// Count dropped,
+	// remained items included.
+	count := func(list []common.Flusher) (n int64) {
+		for _, f := range list {
+			n += f.Dropped()
+			if res.Err != nil {
+				n += int64(f.Remained())
+			}
+		}
+		return
+	}
+	res.Logs, res.Spans = count(logs), count(spans)
+
+	if res.Err != nil || res.Logs > 0 || res.Spans > 0 {
+		common.InternalInfo("<%s> %v", o.name, res)
+		return res
+	}
+	return nil
+}
+
+// sweep
+// publish suppressed counts of logger and span samplers.
+func (o *manager) sweep(force bool) { o.logger.Sweep(force, o.tracer.Sampler()) }
+
 func (o *manager) start(ctx context.Context) {
+	// Validate configuration,
+	// refuse to start in strict mode.
+	if errs := o.config.Validate(); len(errs) > 0 {
+		for _, err := range errs {
+			common.InternalInfo("<%s> config: %v", o.name, err)
+		}
+		if o.config.GetStrict() {
+			panic(&configurer.ValidationError{Errors: errs})
+		}
+	}
+
 	go func() {
 		common.InternalInfo("<%s> start", o.name)
 		if err := o.processor.Start(ctx); err != nil {
@@ -178,12 +549,16 @@ func (o *manager) start(ctx context.Context) {
 		}
 	}()
 
-	// Wait all processors started.
-	mx := 10
-	ms := time.Millisecond
-	for i := 0; i < mx; i++ {
-		time.Sleep(ms)
-		if func() bool {
+	// Wait all processors started
+	// or timed out.
+	ti := time.NewTicker(managerWaitInterval)
+	defer ti.Stop()
+
+	tm := time.NewTimer(managerStartTimeout)
+	defer tm.Stop()
+
+	for {
+		if o.processor.Healthy() && func() bool {
 			if o.logger.GetExecutor() != nil {
 				return o.logger.GetExecutor().Processor().Healthy()
 			}
@@ -195,20 +570,81 @@ func (o *manager) start(ctx context.Context) {
 			return true
 		}() {
 			common.InternalInfo("<%s> started", o.name)
-			break
+			return
+		}
+
+		select {
+		case <-ti.C:
+		case <-tm.C:
+			common.InternalInfo("<%s> start timed out", o.name)
+			return
+		case <-ctx.Done():
+			return
 		}
 	}
 }
 
 func (o *manager) stop() {
-	// Send stop signal.
-	o.processor.Stop()
+	ctx, cancel := context.WithTimeout(context.Background(), managerStopTimeout)
+	defer cancel()
+
+	_ = o.shutdown(ctx)
+}
+
+// swap
+// executors if exporter changed. New executor registered as child and
+// started with context of manager, so it's drained on shutdown. Old
+// executor stopped, remaining items drained, then unregistered.
+func (o *manager) swap(ctx context.Context, event *ReloadEvent) {
+	for _, c := range event.Changes {
+		if !c.Applied {
+			continue
+		}
 
-	// Wait all log and span exported done or timed out.
-	mx := 300
-	ms := time.Millisecond * 100
-	for i := 0; i < mx; i++ {
-		time.Sleep(ms)
+		switch c.Key {
+		case "logger-exporter":
+			if call, ok := builtinLoggers[o.config.GetLoggerExporter()]; ok {
+				if ex := call(o.logger); ex != nil {
+					common.InternalInfo(`<%s> logger executor swapped [name="%s"]`, o.name, ex.Processor().Name())
+
+					old := o.logger.GetExecutor()
+					o.processor.Add(ex.Processor())
+					go func() { _ = ex.Processor().Start(ctx) }()
+					o.logger.SetExecutor(ex)
+					if old != nil {
+						o.retire(old.Processor())
+					}
+				}
+			}
+
+		case "tracer-exporter":
+			if call, ok := builtinTracers[o.config.GetTracerExporter()]; ok {
+				if ex := call(o.tracer); ex != nil {
+					common.InternalInfo(`<%s> tracer executor swapped [name="%s"]`, o.name, ex.Processor().Name())
+
+					old := o.tracer.GetExecutor()
+					o.processor.Add(ex.Processor())
+					go func() { _ = ex.Processor().Start(ctx) }()
+					o.tracer.SetExecutor(ex)
+
+					// Dead letter and fallback executor
+					// kept if shared.
+					if old != nil && old != o.tracer.GetDeadLetter() && old != o.tracer.GetFallback() {
+						o.retire(old.Processor())
+					}
+				}
+			}
+		}
+	}
+}
+
+// wait
+// until executors of logger and tracer stopped.
+func (o *manager) wait(ctx context.Context) error {
+	ti := time.NewTicker(managerWaitInterval)
+	defer ti.Stop()
+
+	for {
 		if func() bool {
 			if o.logger.GetExecutor() != nil {
 				return o.logger.GetExecutor().Processor().Stopped()
@@ -220,9 +656,23 @@ func (o *manager) stop() {
 			}
 			return true
 		}() {
-			break
+			return nil
+		}
+
+		select {
+		case <-ti.C:
+		case <-ctx.Done():
+			return ctx.Err()
 		}
 	}
 }
 
-func init() { new(sync.Once).Do(func() { Manager = (&manager{}).init() }) }
+func init() {
+	new(sync.Once).Do(func() {
+		Manager = (&manager{
+			config: configurer.Config,
+			logger: loggers.Operator,
+			tracer: tracers.Operator,
+		}).init()
+	})
+}
@@ -0,0 +1,140 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-03-10
+
+package log
+
+import (
+	"context"
+	"fmt"
+	"github.com/fuyibing/log/v5/common"
+	"github.com/fuyibing/log/v5/configurer"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestManagerSwapDrainedOnShutdown(t *testing.T) {
+	var (
+		dir  = t.TempDir()
+		file = filepath.Join(dir, "log.yaml")
+	)
+
+	write := func(exporter string) {
+		text := fmt.Sprintf("logger-exporter: %s\ntracer-exporter: memory\nfile-logger:\n  path: %s\n  sync: interval\n  sync-interval: 60000\n", exporter, dir)
+		if err := os.WriteFile(file, []byte(text), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write("term")
+	config, err := configurer.Load(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	m := New(WithConfig(config), WithName("swap"))
+	m.Start(ctx)
+
+	// Swap logger exporter
+	// by reload.
+	write("file")
+	if e := m.Reload(); e.Err != nil {
+		t.Fatalf("reload: %v", e.Err)
+	}
+	ex := m.Logger().GetExecutor()
+	if name := ex.Processor().Name(); name != "logger.file" {
+		t.Fatalf("swap: expect logger.file, got %s", name)
+	}
+	for i := 0; i < 100 && !ex.Processor().Healthy(); i++ {
+		time.Sleep(time.Millisecond * 10)
+	}
+
+	// Registered as child,
+	// old executor unregistered after stopped.
+	parent := m.(*manager).processor
+	if p, ok := parent.Get("logger.file"); !ok || p != ex.Processor() {
+		t.Fatalf("swap: executor not registered")
+	}
+	for i := 0; i < 100; i++ {
+		if _, ok := parent.Get("logger.term"); !ok {
+			break
+		}
+		time.Sleep(time.Millisecond * 10)
+	}
+	if _, ok := parent.Get("logger.term"); ok {
+		t.Fatalf("swap: old executor still registered")
+	}
+
+	for i := 0; i < 10; i++ {
+		m.Logger().Push(nil, common.Error, "swapped %d", i)
+	}
+
+	sc, sd := context.WithTimeout(context.Background(), time.Second*5)
+	defer sd()
+	if err = m.Shutdown(sc); err != nil {
+		t.Fatalf("shutdown: %v", err)
+	}
+	if !ex.Processor().Stopped() {
+		t.Fatalf("shutdown: swapped executor not stopped")
+	}
+
+	// Buffered logs
+	// written when closed.
+	var text string
+	_ = filepath.Walk(filepath.Join(dir, "swap"), func(path string, info os.FileInfo, err error) error {
+		if err == nil && !info.IsDir() {
+			buf, _ := os.ReadFile(path)
+			text += string(buf)
+		}
+		return nil
+	})
+	for i := 0; i < 10; i++ {
+		if s := fmt.Sprintf("swapped %d", i); !strings.Contains(text, s) {
+			t.Fatalf("drain: %q not written", s)
+		}
+	}
+}
+
+func TestManagerConfigCloned(t *testing.T) {
+	config := configurer.New()
+	config.Setter().SetInstance("shared").SetJaegerTracerHeader("X-Origin", "shared")
+
+	a := New(WithConfig(config), WithName("a"), WithSetter(func(s *configurer.Setter) {
+		s.SetJaegerTracerHeader("X-Origin", "a")
+	}))
+	b := New(WithConfig(config), WithName("b"))
+
+	// Changed by manager,
+	// origin and other managers not affected.
+	if s := config.GetInstance(); s != "shared" {
+		t.Fatalf("origin instance changed: %s", s)
+	}
+	if s := config.GetJaegerTracer().GetHeaders()["X-Origin"]; s != "shared" {
+		t.Fatalf("origin header changed: %s", s)
+	}
+	for name, m := range map[string]Management{"a": a, "b": b} {
+		if s := m.(*manager).config.GetInstance(); s != name {
+			t.Fatalf("instance: expect %s, got %s", name, s)
+		}
+	}
+	if s := b.(*manager).config.GetJaegerTracer().GetHeaders()["X-Origin"]; s != "shared" {
+		t.Fatalf("header of b changed: %s", s)
+	}
+}
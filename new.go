@@ -17,10 +17,58 @@ package log
 
 import (
 	"context"
+	"fmt"
+	"github.com/fuyibing/log/v5/configurer"
+	"github.com/fuyibing/log/v5/loggers"
 	"github.com/fuyibing/log/v5/tracers"
 	"net/http"
+	"sync/atomic"
 )
 
+var instances int32
+
+// New returns an independent Management instance with its own
+// configuration, operators, id generator and executors. Global Manager is
+// not affected, configuration given by WithConfig is cloned.
+//
+//   m := log.New(log.WithSetter(func(s *configurer.Setter) {
+//       s.SetTracerExporter("zipkin")
+//   }))
+//   m.Start(ctx)
+//   defer m.Stop()
+func New(opts ...Option) Management {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	// Clone shared config,
+	// instance name and setters are per manager.
+	if o.config == nil {
+		o.config = configurer.New()
+	} else {
+		o.config = o.config.Clone()
+	}
+	for _, fn := range o.setters {
+		fn(o.config.Setter())
+	}
+
+	// Instance name
+	// separate files of managers with same exporter.
+	if o.name != "" {
+		o.config.Setter().SetInstance(o.name)
+	} else if o.config.GetInstance() == "" {
+		o.config.Setter().SetInstance(fmt.Sprintf("instance-%d", atomic.AddInt32(&instances, 1)))
+	}
+
+	logger := loggers.NewOperator(o.config)
+	return (&manager{
+		config: o.config,
+		logger: logger,
+		tracer: tracers.NewOperator(o.config, logger),
+	}).init()
+}
+
 // NewSpan returns a tracers.Span component.
 func NewSpan(name string) (span tracers.Span) {
 	return tracers.NewSpan(name)
@@ -0,0 +1,56 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-03-14
+
+package log
+
+import (
+	"github.com/fuyibing/log/v5/configurer"
+)
+
+type (
+	// Option
+	// used to customize manager created by New.
+	Option func(o *options)
+
+	options struct {
+		config  configurer.Configuration
+		name    string
+		setters []func(setter *configurer.Setter)
+	}
+)
+
+// WithConfig
+// use copy of specified configuration, an independent configuration created
+// by configurer.New if not specified.
+func WithConfig(config configurer.Configuration) Option {
+	return func(o *options) { o.config = config }
+}
+
+// WithName
+// set instance name of manager, files and spilled segments stored in sub
+// directory named by instance. Generated as instance-N if not specified.
+func WithName(name string) Option {
+	return func(o *options) { o.name = name }
+}
+
+// WithSetter
+// change configuration fields before manager created.
+//
+//   log.New(log.WithSetter(func(s *configurer.Setter) {
+//       s.SetLoggerExporter("file")
+//   }))
+func WithSetter(fn func(setter *configurer.Setter)) Option {
+	return func(o *options) { o.setters = append(o.setters, fn) }
+}
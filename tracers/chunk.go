@@ -0,0 +1,157 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-03-13
+
+package tracers
+
+import (
+	"errors"
+	"github.com/fuyibing/log/v5/common"
+	"github.com/fuyibing/log/v5/loggers"
+)
+
+const (
+	// TruncatedTag
+	// added to span whose logs truncated, value is count of dropped logs.
+	TruncatedTag = "log.truncated"
+)
+
+type (
+	// truncatedSpan
+	// wrap origin span with partial logs and marker tag.
+	truncatedSpan struct {
+		Span
+
+		kv   loggers.Kv
+		logs []loggers.Log
+	}
+)
+
+// Chunk
+// encode spans by formatter and call send with bodies not larger than limit
+// bytes. Oversized batch split in halves recursively, logs of single
+// oversized span truncated. First error returned, remaining chunks are sent
+// anyway. Redirected chunks are reported by common.RedirectError.
+func Chunk(formatter Formatter, limit int, spans []Span, send func(body []byte, spans ...Span) error) error {
+	body, err := formatter.Byte(spans...)
+	if err != nil {
+		return err
+	}
+
+	if limit <= 0 || len(body) <= limit {
+		return send(body, spans...)
+	}
+
+	// Split batch
+	// in halves.
+	if len(spans) > 1 {
+		n := len(spans) / 2
+		return join(
+			Chunk(formatter, limit, spans[:n], send),
+			Chunk(formatter, limit, spans[n:], send),
+		)
+	}
+
+	return send(truncate(formatter, limit, spans[0], body))
+}
+
+// /////////////////////////////////////////////////////////////////////////////
+// Access methods
+// /////////////////////////////////////////////////////////////////////////////
+
+func (o *truncatedSpan) Kv() loggers.Kv      { return o.kv }
+func (o *truncatedSpan) Logs() []loggers.Log { return o.logs }
+
+// join
+// result of halves. Redirected counts are summed, send error of other half
+// kept as failure, so both counts are reported.
+func join(a, b error) error {
+	var ra, rb *common.RedirectError
+
+	if a == nil || b == nil {
+		if a == nil {
+			return b
+		}
+		return a
+	}
+
+	errors.As(a, &ra)
+	errors.As(b, &rb)
+	switch {
+	case ra != nil && rb != nil:
+		return &common.RedirectError{Count: ra.Count + rb.Count, Err: ra.Err, Failure: failure(ra.Failure, rb.Failure), Kind: ra.Kind}
+	case ra != nil:
+		return &common.RedirectError{Count: ra.Count, Err: ra.Err, Failure: failure(ra.Failure, b), Kind: ra.Kind}
+	case rb != nil:
+		return &common.RedirectError{Count: rb.Count, Err: rb.Err, Failure: failure(a, rb.Failure), Kind: rb.Kind}
+	}
+	return a
+}
+
+// failure
+// return first not nil error.
+func failure(a, b error) error {
+	if a != nil {
+		return a
+	}
+	return b
+}
+
+// truncate
+// keep most logs of span which encoded body fits limit, search by binary.
+// Body without any logs returned if still oversized, collector decides to
+// accept or not.
+func truncate(formatter Formatter, limit int, sp Span, body []byte) ([]byte, Span) {
+	var (
+		logs = sp.Logs()
+		res  Span
+	)
+
+	encode := func(n int) ([]byte, Span, error) {
+		ts := &truncatedSpan{Span: sp, kv: loggers.Kv{}, logs: logs[:n]}
+		for k, v := range sp.Kv() {
+			ts.kv[k] = v
+		}
+		ts.kv[TruncatedTag] = len(logs) - n
+
+		buf, err := formatter.Byte(ts)
+		return buf, ts, err
+	}
+
+	if len(logs) == 0 {
+		common.InternalInfo("<tracer> span oversized without logs: bytes=%d, limit=%d", len(body), limit)
+		return body, sp
+	}
+
+	buf, ts, err := encode(0)
+	if err != nil {
+		return body, sp
+	}
+	if body, res = buf, ts; len(buf) > limit {
+		common.InternalInfo("<tracer> span oversized after logs truncated: bytes=%d, limit=%d", len(buf), limit)
+		return body, res
+	}
+
+	// Search max logs count
+	// which fits limit, all logs known oversized.
+	for lo, hi := 0, len(logs)-1; lo < hi; {
+		mid := (lo + hi + 1) / 2
+		if buf, ts, err = encode(mid); err == nil && len(buf) <= limit {
+			body, res, lo = buf, ts, mid
+		} else {
+			hi = mid - 1
+		}
+	}
+	return body, res
+}
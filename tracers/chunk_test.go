@@ -0,0 +1,99 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-03-13
+
+package tracers
+
+import (
+	"context"
+	"errors"
+	"github.com/fuyibing/log/v5/common"
+	"strings"
+	"testing"
+)
+
+type (
+	testBatchOption struct{}
+	testFormatter   struct{}
+)
+
+func (testBatchOption) GetBucketBatch() int         { return 10 }
+func (testBatchOption) GetBucketConcurrency() int32 { return 1 }
+func (testBatchOption) GetBucketFrequency() int     { return 10 }
+
+// Byte
+// return 10 bytes per span, names joined.
+func (testFormatter) Byte(vs ...Span) ([]byte, error) {
+	var sb strings.Builder
+	for _, v := range vs {
+		sb.WriteString(v.Name() + strings.Repeat(".", 10-len(v.Name())))
+	}
+	return []byte(sb.String()), nil
+}
+
+func (o testFormatter) String(vs ...Span) (string, error) {
+	buf, err := o.Byte(vs...)
+	return string(buf), err
+}
+
+// TestChunkMixed
+// one half redirected and the other failed, both counts are kept and
+// batcher counts redirected and failed items.
+func TestChunkMixed(t *testing.T) {
+	var (
+		cause  = errors.New("unavailable")
+		reason = errors.New("breaker open")
+		spans  = []Span{&span{name: "r1"}, &span{name: "r2"}, &span{name: "f1"}, &span{name: "f2"}}
+	)
+
+	send := func(_ []byte, spans ...Span) error {
+		if strings.HasPrefix(spans[0].Name(), "r") {
+			return &common.RedirectError{Count: len(spans), Err: reason, Kind: "fallback"}
+		}
+		return cause
+	}
+
+	// Halves of batch
+	// joined into one error.
+	err := Chunk(testFormatter{}, 15, spans, send)
+
+	var re *common.RedirectError
+	if !errors.As(err, &re) {
+		t.Fatalf("expected redirect error, got %v", err)
+	}
+	if re.Count != 2 {
+		t.Fatalf("expected 2 redirected, got %d", re.Count)
+	}
+	if re.Failure != cause {
+		t.Fatalf("expected failure %v, got %v", cause, re.Failure)
+	}
+
+	// Counted by batcher,
+	// sent synchronously if not healthy.
+	batcher := common.NewBatcher[Span]("test", common.NewBucket(10), testBatchOption{}, func() bool { return false }, func(_ context.Context, list ...Span) error {
+		return Chunk(testFormatter{}, 15, list, send)
+	})
+	if err = batcher.Publish(spans...); err != cause {
+		t.Fatalf("expected publish error %v, got %v", cause, err)
+	}
+	if n := batcher.Redirected(); n != 2 {
+		t.Fatalf("expected 2 redirected, got %d", n)
+	}
+	if n := batcher.Failed(); n != 2 {
+		t.Fatalf("expected 2 failed, got %d", n)
+	}
+	if n := batcher.Sent(); n != 0 {
+		t.Fatalf("expected 0 sent, got %d", n)
+	}
+}
@@ -0,0 +1,110 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-03-07
+
+package tracers
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/fuyibing/log/v5/common"
+	"github.com/fuyibing/log/v5/loggers"
+	"time"
+)
+
+type (
+	codec struct {
+		logger   common.Codec
+		operator OperatorManager
+	}
+
+	codecSpan struct {
+		EndTime      time.Time         `json:"end-time"`
+		Kv           loggers.Kv        `json:"kv,omitempty"`
+		Logs         []json.RawMessage `json:"logs,omitempty"`
+		Name         string            `json:"name"`
+		ParentSpanId string            `json:"parent-span-id"`
+		SpanId       string            `json:"span-id"`
+		StartTime    time.Time         `json:"start-time"`
+		TraceId      string            `json:"trace-id"`
+		TraceName    string            `json:"trace-name"`
+	}
+)
+
+// NewCodec
+// return codec for Span component, used when span spilled to disk. Decoded
+// spans are bound to specified operator.
+func NewCodec(operator OperatorManager) common.Codec {
+	return &codec{logger: loggers.NewCodec(), operator: operator}
+}
+
+func (o *codec) Decode(data []byte) (item interface{}, err error) {
+	v := &codecSpan{}
+	if err = json.Unmarshal(data, v); err != nil {
+		return
+	}
+
+	t := (&trace{name: v.TraceName, operator: o.operator}).init()
+	t.traceId = o.operator.Generator().TraceIdFromHex(v.TraceId)
+	t.ctx = context.WithValue(context.Background(), ContextKey, t)
+
+	x := &span{
+		kv: v.Kv, name: v.Name,
+		logs:         make([]loggers.Log, 0),
+		parentSpanId: o.operator.Generator().SpanIdFromHex(v.ParentSpanId),
+		spanId:       o.operator.Generator().SpanIdFromHex(v.SpanId),
+		startTime:    v.StartTime, endTime: v.EndTime,
+		trace: t,
+	}
+	if x.kv == nil {
+		x.kv = loggers.Kv{}
+	}
+	x.ctx = context.WithValue(t.ctx, ContextKey, x)
+
+	for _, raw := range v.Logs {
+		var g interface{}
+		if g, err = o.logger.Decode(raw); err != nil {
+			return
+		}
+		x.logs = append(x.logs, g.(loggers.Log))
+	}
+
+	item = x
+	return
+}
+
+func (o *codec) Encode(item interface{}) (data []byte, err error) {
+	v, ok := item.(Span)
+	if !ok {
+		return nil, fmt.Errorf("unsupported codec item: %T", item)
+	}
+
+	x := &codecSpan{
+		EndTime: v.StartTime().Add(v.Duration()), Kv: v.Kv(),
+		Logs: make([]json.RawMessage, 0),
+		Name: v.Name(), ParentSpanId: v.ParentSpanId().String(), SpanId: v.SpanId().String(),
+		StartTime: v.StartTime(), TraceId: v.Trace().TraceId().String(), TraceName: v.Trace().Name(),
+	}
+
+	for _, log := range v.Logs() {
+		var buf []byte
+		if buf, err = o.logger.Encode(log); err != nil {
+			return
+		}
+		x.Logs = append(x.Logs, buf)
+	}
+
+	return json.Marshal(x)
+}
@@ -16,6 +16,7 @@
 package tracers
 
 import (
+	"github.com/fuyibing/log/v5/common"
 	"github.com/fuyibing/util/v8/process"
 )
 
@@ -36,3 +37,33 @@ type (
 		SetFormatter(formatter Formatter)
 	}
 )
+
+// DeadLetter
+// publish spans which given up by exporter to dead letter executor of
+// operator. Origin error returned if dead letter executor not configured or
+// failed, otherwise common.RedirectError.
+func DeadLetter(operator OperatorManager, name string, err error, spans ...Span) error {
+	return redirect(operator.GetDeadLetter(), "dead letter", name, err, spans...)
+}
+
+// Fallback
+// publish spans which rejected by open circuit breaker to fallback
+// executor of operator. Origin error returned if fallback executor not
+// configured or failed, otherwise common.RedirectError.
+func Fallback(operator OperatorManager, name string, err error, spans ...Span) error {
+	return redirect(operator.GetFallback(), "fallback", name, err, spans...)
+}
+
+func redirect(ex Executor, kind, name string, err error, spans ...Span) error {
+	if ex == nil {
+		return err
+	}
+
+	if de := ex.Publish(spans...); de != nil {
+		common.InternalInfo("<%s> %s: %v", name, kind, de)
+		return err
+	}
+
+	common.InternalInfo("<%s> %s: spans=%d, %v", name, kind, len(spans), err)
+	return &common.RedirectError{Count: len(spans), Err: err, Kind: kind}
+}
@@ -16,10 +16,13 @@
 package tracers
 
 import (
+	"context"
 	"fmt"
 	"github.com/fuyibing/log/v5/common"
+	"github.com/fuyibing/log/v5/configurer"
 	"github.com/fuyibing/log/v5/loggers"
 	"net"
+	"net/http"
 	"os"
 	"runtime"
 	"strings"
@@ -39,44 +42,125 @@ type (
 	// OperatorManager
 	// for tracer operations.
 	OperatorManager interface {
+		// Config
+		// return configuration of operator.
+		Config() configurer.Configuration
+
 		// Generator
 		// return id generator.
 		Generator() (generator *id)
 
+		// GetDeadLetter
+		// return executor which receive spans given up by exporter.
+		GetDeadLetter() (executor Executor)
+
 		// GetExecutor
 		// return tracer executor.
 		GetExecutor() (executor Executor)
 
+		// GetFallback
+		// return executor which receive spans while circuit breaker of
+		// exporter is open.
+		GetFallback() (executor Executor)
+
 		// GetResource
 		// return operator key/value pairs.
 		GetResource() (kv loggers.Kv)
 
+		// Logger
+		// return logger operator which span logs pushed to.
+		Logger() loggers.OperatorManager
+
+		// NewSpan
+		// return a Span component bound to operator.
+		NewSpan(name string) Span
+
+		// NewSpanFromContext
+		// return a Span component bound to operator, based on specified
+		// context.Context. Span in context reused with its own operator.
+		NewSpanFromContext(ctx context.Context, name string) Span
+
+		// NewSpanFromRequest
+		// return a Span component bound to operator, based on http request.
+		NewSpanFromRequest(req *http.Request, name string) Span
+
 		// Push
 		// span component on to executor.
 		Push(span Span)
 
+		// Sampler
+		// return sampler of span logs.
+		Sampler() *loggers.Sampler
+
+		// SetDeadLetter
+		// configure dead letter executor.
+		SetDeadLetter(executor Executor)
+
 		// SetExecutor
 		// configure tracer executor.
 		SetExecutor(executor Executor)
+
+		// SetFallback
+		// configure fallback executor.
+		SetFallback(executor Executor)
 	}
 
 	operator struct {
-		executor  Executor
-		generator *id
-		name      string
-		resource  loggers.Kv
+		config     configurer.Configuration
+		deadLetter Executor
+		executor   Executor
+		fallback   Executor
+		generator  *id
+		logger     loggers.OperatorManager
+		mu         sync.RWMutex
+		name       string
+		resource   loggers.Kv
+		sampler    *loggers.Sampler
 	}
 )
 
+// NewOperator
+// create and return an independent tracer operator with specified
+// configuration and logger operator, id generator is not shared.
+func NewOperator(config configurer.Configuration, logger loggers.OperatorManager) OperatorManager {
+	return (&operator{config: config, logger: logger}).init()
+}
+
 // /////////////////////////////////////////////////////////////////////////////
 // Interface methods
 // /////////////////////////////////////////////////////////////////////////////
 
-func (o *operator) Generator() (generator *id)       { return o.generator }
-func (o *operator) GetExecutor() (executor Executor) { return o.executor }
-func (o *operator) GetResource() (kv loggers.Kv)     { return o.resource }
-func (o *operator) Push(span Span)                   { o.push(span) }
-func (o *operator) SetExecutor(executor Executor)    { o.executor = executor }
+func (o *operator) Config() configurer.Configuration   { return o.config }
+func (o *operator) Generator() (generator *id)         { return o.generator }
+func (o *operator) GetDeadLetter() (executor Executor) { return o.deadLetter }
+func (o *operator) GetFallback() (executor Executor)   { return o.fallback }
+func (o *operator) GetResource() (kv loggers.Kv)       { return o.resource }
+func (o *operator) Logger() loggers.OperatorManager    { return o.logger }
+func (o *operator) NewSpan(name string) Span           { return o.newSpan(name) }
+func (o *operator) Push(span Span)                     { o.push(span) }
+func (o *operator) Sampler() *loggers.Sampler          { return o.sampler }
+func (o *operator) SetDeadLetter(executor Executor)    { o.deadLetter = executor }
+func (o *operator) SetFallback(executor Executor)      { o.fallback = executor }
+
+func (o *operator) GetExecutor() (executor Executor) {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	return o.executor
+}
+
+func (o *operator) SetExecutor(executor Executor) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.executor = executor
+}
+
+func (o *operator) NewSpanFromContext(ctx context.Context, name string) Span {
+	return o.newSpanFromContext(ctx, name)
+}
+
+func (o *operator) NewSpanFromRequest(req *http.Request, name string) Span {
+	return o.newSpanFromRequest(req, name)
+}
 
 // /////////////////////////////////////////////////////////////////////////////
 // Access and constructor
@@ -86,6 +170,7 @@ func (o *operator) init() *operator {
 	o.generator = (&id{}).init()
 	o.name = "tracers.operator"
 	o.resource = loggers.Kv{}
+	o.sampler = loggers.NewSampler(o.config)
 
 	o.initResource()
 	return o
@@ -113,13 +198,67 @@ func (o *operator) initResource() {
 	}
 }
 
+func (o *operator) newSpan(name string) Span {
+	t := (&trace{name: name, operator: o}).init()
+	t.traceId = o.generator.TraceIdNew()
+	t.ctx = context.WithValue(context.Background(), ContextKey, t)
+	return t.New(name)
+}
+
+func (o *operator) newSpanFromContext(ctx context.Context, name string) Span {
+	// Tracer reuse.
+	if g := ctx.Value(ContextKey); g != nil {
+		// Return child span.
+		if v, ok := g.(Span); ok {
+			return v.Child(name)
+		}
+
+		// Return root span of a trace.
+		if v, ok := g.(Trace); ok {
+			return v.New(name)
+		}
+	}
+
+	// Return new span.
+	t := (&trace{name: name, operator: o}).init()
+	t.traceId = o.generator.TraceIdNew()
+	t.ctx = context.WithValue(ctx, ContextKey, t)
+	return t.New(name)
+}
+
+func (o *operator) newSpanFromRequest(req *http.Request, name string) Span {
+	t := (&trace{name: name, operator: o}).init()
+	t.parseRequestField(req)
+	t.parseRequestId(req)
+
+	if !t.spanId.IsValid() || !t.traceId.IsValid() {
+		t.traceId = o.generator.TraceIdNew()
+		t.spanId = SpanId{}
+	}
+
+	t.ctx = context.WithValue(context.Background(), ContextKey, t)
+	return t.New(name)
+}
+
 func (o *operator) push(span Span) {
-	if o.executor == nil {
+	executor := o.GetExecutor()
+	if executor == nil {
 		return
 	}
-	if err := o.executor.Publish(span); err != nil {
+
+	// Redact sensitive data of span
+	// and logs before formatted.
+	if r := o.config.GetRedact(); r.Enabled() {
+		span.Kv().Redact(r)
+		for _, log := range span.Logs() {
+			loggers.Redact(r, log)
+		}
+	}
+	if err := executor.Publish(span); err != nil {
 		common.InternalFatal("<%s> send: %v", o.name, err)
 	}
 }
 
-func init() { new(sync.Once).Do(func() { Operator = (&operator{}).init() }) }
+func init() {
+	new(sync.Once).Do(func() { Operator = NewOperator(configurer.Config, loggers.Operator) })
+}
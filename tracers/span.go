@@ -17,13 +17,18 @@ package tracers
 
 import (
 	"context"
-	"github.com/fuyibing/log/v5/configurer"
 	"github.com/fuyibing/log/v5/loggers"
 	"net/http"
 	"sync"
 	"time"
 )
 
+const (
+	// SpanErrorKey
+	// tag of errored span, followed by opentracing convention.
+	SpanErrorKey = "error"
+)
+
 type (
 	// Span
 	// component for tracer.
@@ -58,50 +63,18 @@ type (
 )
 
 // NewSpan returns a Span component.
-func NewSpan(name string) Span {
-	t := (&trace{name: name}).init()
-	t.traceId = Operator.Generator().TraceIdNew()
-	t.ctx = context.WithValue(context.Background(), ContextKey, t)
-	return t.New(name)
-}
+func NewSpan(name string) Span { return Operator.NewSpan(name) }
 
 // NewSpanFromContext returns a Span component, based on specified
 // context.Context.
 func NewSpanFromContext(ctx context.Context, name string) Span {
-	// Tracer reuse.
-	if g := ctx.Value(ContextKey); g != nil {
-		// Return child span.
-		if v, ok := g.(Span); ok {
-			return v.Child(name)
-		}
-
-		// Return root span of a trace.
-		if v, ok := g.(Trace); ok {
-			return v.New(name)
-		}
-	}
-
-	// Return new span.
-	t := (&trace{name: name}).init()
-	t.traceId = Operator.Generator().TraceIdNew()
-	t.ctx = context.WithValue(ctx, ContextKey, t)
-	return t.New(name)
+	return Operator.NewSpanFromContext(ctx, name)
 }
 
 // NewSpanFromRequest returns a Span component, based on http request
 // and context.Context.
 func NewSpanFromRequest(req *http.Request, name string) Span {
-	t := (&trace{name: name}).init()
-	t.parseRequestField(req)
-	t.parseRequestId(req)
-
-	if !t.spanId.IsValid() || !t.traceId.IsValid() {
-		t.traceId = Operator.Generator().TraceIdNew()
-		t.spanId = SpanId{}
-	}
-
-	t.ctx = context.WithValue(context.Background(), ContextKey, t)
-	return t.New(name)
+	return Operator.NewSpanFromRequest(req, name)
 }
 
 // /////////////////////////////////////////////////////////////////////////////
@@ -109,14 +82,14 @@ func NewSpanFromRequest(req *http.Request, name string) Span {
 // /////////////////////////////////////////////////////////////////////////////
 
 func (o *span) ApplyRequest(req *http.Request) {
-	req.Header.Set(configurer.Config.GetOpenTracingTraceId(), o.trace.TraceId().String())
-	req.Header.Set(configurer.Config.GetOpenTracingSpanId(), o.trace.SpanId().String())
-	req.Header.Set(configurer.Config.GetOpenTracingSampled(), "1")
+	config := o.operator().Config()
+	req.Header.Set(config.GetOpenTracingTraceId(), o.trace.TraceId().String())
+	req.Header.Set(config.GetOpenTracingSpanId(), o.trace.SpanId().String())
+	req.Header.Set(config.GetOpenTracingSampled(), "1")
 }
 
 func (o *span) Child(name string) Span {
-	v := (&span{name: name}).init()
-	v.trace = o.trace
+	v := (&span{name: name, trace: o.trace}).init()
 	v.parentSpanId = o.spanId
 	v.ctx = context.WithValue(o.ctx, ContextKey, v)
 	return v
@@ -130,7 +103,7 @@ func (o *span) End() {
 	o.endTime = time.Now()
 	o.Unlock()
 
-	Operator.Push(o)
+	o.operator().Push(o)
 }
 
 func (o *span) Kv() loggers.Kv     { return o.kv }
@@ -160,10 +133,28 @@ func (o *span) addLog(log loggers.Log) {
 	o.logs = append(o.logs, log)
 }
 
+// operator
+// return operator of trace which span created by.
+func (o *span) operator() OperatorManager {
+	if t, ok := o.trace.(*trace); ok && t.operator != nil {
+		return t.operator
+	}
+	return Operator
+}
+
+// setError
+// mark span as error, exported as error tag.
+func (o *span) setError() {
+	o.Lock()
+	defer o.Unlock()
+
+	o.kv.Add(SpanErrorKey, true)
+}
+
 func (o *span) init() *span {
 	o.kv = loggers.Kv{}
 	o.logs = make([]loggers.Log, 0)
-	o.spanId = Operator.Generator().SpanIdNew()
+	o.spanId = o.operator().Generator().SpanIdNew()
 	o.startTime = time.Now()
 	return o
 }
@@ -17,7 +17,6 @@ package tracers
 
 import (
 	"github.com/fuyibing/log/v5/common"
-	"github.com/fuyibing/log/v5/configurer"
 	"github.com/fuyibing/log/v5/loggers"
 	"sync"
 )
@@ -30,6 +29,12 @@ type (
 	SpanLogger interface {
 		Add(key string, value interface{}) SpanLogger
 		Debug(text string, args ...interface{})
+
+		// Err
+		// bind error on next log, cause chain recorded as error.chain, stack
+		// of error used if carried and span marked as error.
+		Err(err error) SpanLogger
+
 		Error(text string, args ...interface{})
 		Fatal(text string, args ...interface{})
 		Info(text string, args ...interface{})
@@ -39,6 +44,7 @@ type (
 	spanLogger struct {
 		sync.RWMutex
 
+		err  error
 		kv   loggers.Kv
 		span *span
 	}
@@ -46,6 +52,7 @@ type (
 
 func (o *spanLogger) Add(key string, value interface{}) SpanLogger { return o.add(key, value) }
 func (o *spanLogger) Debug(format string, args ...interface{})     { o.send(common.Debug, format, args...) }
+func (o *spanLogger) Err(err error) SpanLogger                     { return o.setErr(err) }
 func (o *spanLogger) Info(format string, args ...interface{})      { o.send(common.Info, format, args...) }
 func (o *spanLogger) Warn(format string, args ...interface{})      { o.send(common.Warn, format, args...) }
 func (o *spanLogger) Error(format string, args ...interface{})     { o.send(common.Error, format, args...) }
@@ -64,6 +71,7 @@ func (o *spanLogger) add(key string, value interface{}) SpanLogger {
 }
 
 func (o *spanLogger) after() {
+	o.err = nil
 	o.kv = nil
 	o.span = nil
 }
@@ -73,16 +81,61 @@ func (o *spanLogger) before(span *span) {
 	o.span = span
 }
 
+// sample
+// return true if span log passed by sampler, summary added to span if
+// logs suppressed in previous interval.
+func (o *spanLogger) sample(operator OperatorManager, level common.Level, format string) bool {
+	if !operator.Config().GetLoggerSampling().GetSpan() {
+		return true
+	}
+
+	passed, suppressed := operator.Sampler().Sample(level, format)
+	if suppressed > 0 {
+		o.span.addLog(loggers.NewSamplerSummary(level, format, suppressed))
+	}
+	return passed
+}
+
+func (o *spanLogger) setErr(err error) SpanLogger {
+	o.Lock()
+	defer o.Unlock()
+
+	o.err = err
+	return o
+}
+
 func (o *spanLogger) send(level common.Level, format string, args ...interface{}) {
+	operator := o.span.operator()
+
+	// Mark span
+	// as error.
+	if o.err != nil {
+		o.span.setError()
+	}
+
+	var (
+		sid = o.span.SpanId().String()
+		tid = o.span.Trace().TraceId().String()
+	)
+
 	// Push to logger executor.
-	loggers.Operator.Push(o.kv, level, format, args...)
+	operator.Logger().PushSpan(tid, sid, o.kv, o.err, level, format, args...)
 
 	// Push to tracer executor.
-	if configurer.Config.LevelEnabled(level) {
-		log := loggers.NewLog(level, format, args...)
+	if operator.Config().LevelEnabled(level) && o.sample(operator, level, format) {
+		log := loggers.NewLog(level, format, args...).SetSpan(tid, sid)
 		if len(o.kv) > 0 {
 			log.SetKv(o.kv)
 		}
+		if o.err != nil {
+			log.SetKv(loggers.Kv{loggers.ErrorChainKey: loggers.ErrorChain(o.err)})
+			if s := loggers.ErrorStack(o.err); s != nil {
+				log.SetStack(s)
+			}
+		}
+		if !log.Stack() && operator.Config().StackEnabled(level) {
+			log.SetStack(common.NewLazyStack(0, common.StackDepth))
+		}
 		o.span.addLog(log)
 	}
 
@@ -17,7 +17,6 @@ package tracers
 
 import (
 	"context"
-	"github.com/fuyibing/log/v5/configurer"
 	"github.com/fuyibing/log/v5/loggers"
 	"net/http"
 )
@@ -39,9 +38,10 @@ type (
 	}
 
 	trace struct {
-		ctx  context.Context
-		kv   loggers.Kv
-		name string
+		ctx      context.Context
+		kv       loggers.Kv
+		name     string
+		operator OperatorManager
 
 		spanId  SpanId
 		traceId TraceId
@@ -69,10 +69,9 @@ func (o *trace) init() *trace {
 }
 
 func (o *trace) new(name string) Span {
-	v := (&span{name: name}).init()
+	v := (&span{name: name, trace: o}).init()
 	v.kv.Copy(o.kv)
 	v.parentSpanId = o.spanId
-	v.trace = o
 
 	v.ctx = context.WithValue(o.ctx, ContextKey, v)
 	return v
@@ -92,8 +91,8 @@ func (o *trace) parseRequestId(req *http.Request) {
 	//   {
 	//     "X-B3-Traceid": "trace id"
 	//   }
-	if s := req.Header.Get(configurer.Config.GetOpenTracingTraceId()); s != "" {
-		if v := Operator.Generator().TraceIdFromHex(s); v.IsValid() {
+	if s := req.Header.Get(o.operator.Config().GetOpenTracingTraceId()); s != "" {
+		if v := o.operator.Generator().TraceIdFromHex(s); v.IsValid() {
 			o.traceId = v
 		}
 	}
@@ -103,8 +102,8 @@ func (o *trace) parseRequestId(req *http.Request) {
 	//   {
 	//     "X-B3-Spanid": "span id"
 	//   }
-	if s := req.Header.Get(configurer.Config.GetOpenTracingSpanId()); s != "" {
-		if v := Operator.Generator().SpanIdFromHex(s); v.IsValid() {
+	if s := req.Header.Get(o.operator.Config().GetOpenTracingSpanId()); s != "" {
+		if v := o.operator.Generator().SpanIdFromHex(s); v.IsValid() {
 			o.spanId = v
 		}
 	}
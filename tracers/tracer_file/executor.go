@@ -19,36 +19,44 @@ package tracer_file
 
 import (
 	"context"
-	"fmt"
 	"github.com/fuyibing/log/v5/common"
 	"github.com/fuyibing/log/v5/configurer"
 	"github.com/fuyibing/log/v5/tracers"
 	"github.com/fuyibing/util/v8/process"
-	"os"
-	"sync"
-	"sync/atomic"
-	"time"
 )
 
 type executor struct {
-	sync.RWMutex
-
-	bucket     common.Bucket
-	folders    map[string]bool
-	formatter  tracers.Formatter
-	name       string
-	processor  process.Processor
-	processing int32
+	batcher   common.Batcher[tracers.Span]
+	config    configurer.Configuration
+	formatter tracers.Formatter
+	json      tracers.Formatter
+	text      tracers.Formatter
+	name      string
+	operator  tracers.OperatorManager
+	processor process.Processor
+	writer    common.FileWriter
 }
 
-func New() tracers.Executor { return (&executor{}).init() }
+func New() tracers.Executor { return NewWith(tracers.Operator) }
+
+// NewWith
+// create and return executor bound to specified operator.
+func NewWith(operator tracers.OperatorManager) tracers.Executor {
+	return (&executor{config: operator.Config(), operator: operator}).init()
+}
 
 // /////////////////////////////////////////////////////////////////////////////
 // Interface methods
 // /////////////////////////////////////////////////////////////////////////////
 
+func (o *executor) Dropped() int64                      { return o.batcher.Dropped() }
+func (o *executor) Failed() int64                       { return o.batcher.Failed() }
+func (o *executor) Flush(ctx context.Context) error     { return o.batcher.Flush(ctx) }
 func (o *executor) Processor() process.Processor        { return o.processor }
-func (o *executor) Publish(spans ...tracers.Span) error { return o.publish(spans...) }
+func (o *executor) Publish(spans ...tracers.Span) error { return o.batcher.Publish(spans...) }
+func (o *executor) Redirected() int64                   { return o.batcher.Redirected() }
+func (o *executor) Remained() int                       { return o.batcher.Bucket().Count() }
+func (o *executor) Sent() int64                         { return o.batcher.Sent() }
 func (o *executor) SetFormatter(v tracers.Formatter)    { o.formatter = v }
 
 // /////////////////////////////////////////////////////////////////////////////
@@ -56,41 +64,16 @@ func (o *executor) SetFormatter(v tracers.Formatter)    { o.formatter = v }
 // /////////////////////////////////////////////////////////////////////////////
 
 func (o *executor) onAfter(ctx context.Context) (ignored bool) {
-	cc := atomic.LoadInt32(&o.processing)
-
-	// 处理完成.
-	// - 并行降低
-	// - 空数据桶.
-	if cc == 0 && o.bucket.IsEmpty() {
-		return
-	}
+	o.batcher.Drain(ctx)
 
-	// 加大并行.
-	if cc < configurer.Config.GetBucketConcurrency() {
-		go o.pop()
+	// 关闭文件.
+	if err := o.writer.Close(); err != nil {
+		common.InternalInfo("<%s> close: %v", o.name, err)
 	}
-
-	// 定时延后.
-	time.Sleep(time.Millisecond * 100)
-	return o.onAfter(ctx)
+	return
 }
 
-func (o *executor) onCall(ctx context.Context) (ignored bool) {
-	common.InternalInfo("<%s> signal listening", o.name)
-
-	// 定时收取.
-	ti := time.NewTicker(time.Duration(configurer.Config.GetBucketFrequency()) * time.Millisecond)
-
-	// 监听信号.
-	for {
-		select {
-		case <-ti.C:
-			go o.pop()
-		case <-ctx.Done():
-			return
-		}
-	}
-}
+func (o *executor) onCall(ctx context.Context) (ignored bool) { return o.batcher.Listen(ctx) }
 
 func (o *executor) onPanic(_ context.Context, v interface{}) {
 	common.InternalFatal("<%s> fatal: %v", o.name, v)
@@ -100,127 +83,49 @@ func (o *executor) onPanic(_ context.Context, v interface{}) {
 // Access methods
 // /////////////////////////////////////////////////////////////////////////////
 
+// format
+// return formatter set by SetFormatter, otherwise builtin formatter of
+// configured format, resolved on each write so reloaded format applied.
+func (o *executor) format() tracers.Formatter {
+	if o.formatter != nil {
+		return o.formatter
+	}
+	if o.config.GetFileTracer().GetFormat() == common.FileFormatJson {
+		return o.json
+	}
+	return o.text
+}
+
 func (o *executor) init() *executor {
-	o.bucket = common.NewBucket(configurer.Config.GetBucketCapacity())
-	o.folders = make(map[string]bool)
-	o.formatter = (&formatter{}).init()
 	o.name = "tracer.file"
+	o.json = (&jsonFormatter{config: o.config}).init()
+	o.text = (&formatter{}).init()
 	o.processor = process.New(o.name).
 		After(o.onAfter).
 		Callback(o.onCall).
 		Panic(o.onPanic)
-
-	return o
-}
-
-func (o *executor) pop() {
-	// 限流控制.
-	if cc := atomic.AddInt32(&o.processing, 1); cc > configurer.Config.GetBucketConcurrency() {
-		atomic.AddInt32(&o.processing, -1)
-		return
-	}
-
-	// 取出数据.
-	var (
-		list []tracers.Span
-		redo = false
+	o.batcher = common.NewBatcher[tracers.Span](o.name, tracers.NewBucket(o.name, o.operator),
+		o.config, o.processor.Healthy, o.send,
 	)
-	if items, _, count := o.bucket.Popn(configurer.Config.GetBucketBatch()); count > 0 {
-		list = make([]tracers.Span, 0)
-		redo = true
-		// 遍历数据.
-		for _, item := range items {
-			if v, ok := item.(tracers.Span); ok {
-				list = append(list, v)
-			}
-		}
-		// 处理跨度.
-		if len(list) > 0 {
-			if err := o.send(list...); err != nil {
-				common.InternalInfo("<%s> send: %v", o.name, err)
-			}
-		}
-	}
+	o.writer = common.NewFileWriter(o.name, o.config.GetInstance(), func() common.FileOption {
+		return o.config.GetFileTracer()
+	})
 
-	// 恢复并行.
-	atomic.AddInt32(&o.processing, -1)
-	if redo {
-		o.pop()
-	}
-}
-
-func (o *executor) publish(spans ...tracers.Span) (err error) {
-	var total int
-
-	// 健康进程.
-	if o.processor.Healthy() {
-		// 数据入桶.
-		for _, log := range spans {
-			if total, err = o.bucket.Add(log); err != nil {
-				return
-			}
-		}
-
-		// 立即消费.
-		if total >= configurer.Config.GetBucketBatch() {
-			go o.pop()
-		}
-		return
-	}
-
-	// 立即写入.
-	return o.send(spans...)
+	return o
 }
 
-func (o *executor) send(spans ...tracers.Span) (err error) {
+func (o *executor) send(_ context.Context, spans ...tracers.Span) (err error) {
 	if len(spans) == 0 {
 		return
 	}
 
-	var (
-		fp   *os.File
-		path string
-		text string
-	)
-
-	// 校验目录.
-	if path, err = o.validate(spans[0].StartTime()); err != nil {
-		return
-	}
+	var text string
 
 	// 格式跨度.
-	if text, err = o.formatter.String(spans...); err != nil {
-		return
-	}
-
-	// 打开文件.
-	if fp, err = os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_RDWR, os.ModePerm); err != nil {
+	if text, err = o.format().String(spans...); err != nil {
 		return
 	}
 
-	// 关闭文件.
-	defer func() { _ = fp.Close() }()
-
 	// 写入日志.
-	_, err = fp.WriteString(fmt.Sprintf("%s\n", text))
-	return
-}
-
-func (o *executor) validate(t time.Time) (string, error) {
-	var (
-		dir  = fmt.Sprintf("%s/%s", configurer.Config.GetFileTracer().GetPath(), t.Format(configurer.Config.GetFileTracer().GetFolder()))
-		err  error
-		path = fmt.Sprintf("%v/%s.%s", dir, t.Format(configurer.Config.GetFileTracer().GetName()), configurer.Config.GetFileTracer().GetExt())
-	)
-
-	o.Lock()
-	defer o.Unlock()
-
-	if _, ok := o.folders[dir]; !ok {
-		if err = os.MkdirAll(dir, os.ModePerm); err == nil {
-			o.folders[dir] = true
-		}
-	}
-
-	return path, err
+	return o.writer.Write(spans[0].StartTime(), text)
 }
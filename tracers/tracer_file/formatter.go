@@ -23,7 +23,12 @@ import (
 
 type formatter struct{}
 
-func (o *formatter) Byte(_ ...tracers.Span) (body []byte, err error) { return }
+// Byte
+// 转成字节.
+func (o *formatter) Byte(vs ...tracers.Span) ([]byte, error) {
+	text, err := o.String(vs...)
+	return []byte(text), err
+}
 
 func (o *formatter) String(vs ...tracers.Span) (text string, err error) {
 	var list = make([]string, 0)
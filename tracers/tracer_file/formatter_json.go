@@ -0,0 +1,150 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-03-25
+
+package tracer_file
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"github.com/fuyibing/log/v5/common"
+	"github.com/fuyibing/log/v5/configurer"
+	"github.com/fuyibing/log/v5/loggers"
+	"github.com/fuyibing/log/v5/tracers"
+	"time"
+)
+
+var (
+	// Field names of span record,
+	// conflicted keys of flat fields prefixed.
+	jsonReserved = map[string]bool{
+		"duration_us": true, "fields": true, "logs": true, "name": true,
+		"parent_span_id": true, "span_id": true, "time": true, "trace_id": true,
+	}
+)
+
+type (
+	// jsonFormatter
+	// 每个跨度输出为一行 JSON, 包含跨度日志.
+	jsonFormatter struct {
+		config configurer.ConfigTracerFile
+	}
+
+	// jsonSpan
+	// record of span, logs nested without trace and span id.
+	//
+	//   {"time":"...","name":"...","trace_id":"...","span_id":"...","duration_us":105,"fields":{},"logs":[]}
+	jsonSpan struct {
+		Time         string            `json:"time"`
+		Name         string            `json:"name"`
+		TraceId      string            `json:"trace_id"`
+		SpanId       string            `json:"span_id"`
+		ParentSpanId string            `json:"parent_span_id,omitempty"`
+		DurationUs   int64             `json:"duration_us"`
+		Fields       loggers.Kv        `json:"fields,omitempty"`
+		Logs         []json.RawMessage `json:"logs,omitempty"`
+	}
+)
+
+// Byte
+// 转成 JSON Lines.
+func (o *jsonFormatter) Byte(vs ...tracers.Span) ([]byte, error) {
+	var buf bytes.Buffer
+	for i, v := range vs {
+		body, err := o.format(v)
+		if err != nil {
+			return nil, err
+		}
+		if i > 0 {
+			buf.WriteByte('\n')
+		}
+		buf.Write(body)
+	}
+	return buf.Bytes(), nil
+}
+
+// String
+// 转成字符串.
+func (o *jsonFormatter) String(vs ...tracers.Span) (string, error) {
+	body, err := o.Byte(vs...)
+	return string(body), err
+}
+
+// format
+// 格式化.
+func (o *jsonFormatter) format(v tracers.Span) ([]byte, error) {
+	fields := o.config.GetFileTracer().GetFields()
+	x := &jsonSpan{
+		Time:       v.StartTime().Format(time.RFC3339Nano),
+		Name:       v.Name(),
+		TraceId:    v.Trace().TraceId().String(),
+		SpanId:     v.SpanId().String(),
+		DurationUs: v.Duration().Microseconds(),
+		Fields:     v.Kv(),
+	}
+	if pid := v.ParentSpanId(); pid.IsValid() {
+		x.ParentSpanId = pid.String()
+	}
+
+	// 跨度日志.
+	for _, log := range v.Logs() {
+		item := loggers.NewJsonLog(log)
+		item.TraceId, item.SpanId = "", ""
+		body, err := item.Marshal(fields)
+		if err != nil {
+			return nil, err
+		}
+		x.Logs = append(x.Logs, body)
+	}
+
+	body, err := x.marshal(fields)
+	if err != nil && len(x.Fields) > 0 {
+		kv := loggers.Kv{}
+		for k, val := range x.Fields {
+			kv[k] = fmt.Sprintf("%v", val)
+		}
+		x.Fields = kv
+		return x.marshal(fields)
+	}
+	return body, err
+}
+
+func (o *jsonFormatter) init() *jsonFormatter { return o }
+
+// marshal
+// return json bytes, span fields merged into record by
+// loggers.FlattenFields if fields is flat.
+func (o *jsonSpan) marshal(fields common.FileFields) ([]byte, error) {
+	if fields != common.FileFieldsFlat || len(o.Fields) == 0 {
+		return json.Marshal(o)
+	}
+
+	m := map[string]interface{}{
+		"duration_us": o.DurationUs,
+		"name":        o.Name,
+		"span_id":     o.SpanId,
+		"time":        o.Time,
+		"trace_id":    o.TraceId,
+	}
+	if o.ParentSpanId != "" {
+		m["parent_span_id"] = o.ParentSpanId
+	}
+	if len(o.Logs) > 0 {
+		m["logs"] = o.Logs
+	}
+
+	loggers.FlattenFields(m, o.Fields, jsonReserved)
+	return json.Marshal(m)
+}
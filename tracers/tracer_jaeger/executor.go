@@ -18,7 +18,6 @@
 package tracer_jaeger
 
 import (
-	"bytes"
 	"context"
 	"encoding/base64"
 	"fmt"
@@ -27,68 +26,56 @@ import (
 	"github.com/fuyibing/log/v5/tracers"
 	"github.com/fuyibing/util/v8/process"
 	"github.com/valyala/fasthttp"
-	"net/http"
-	"sync/atomic"
-	"time"
 )
 
 type executor struct {
-	bucket     common.Bucket
-	formatter  tracers.Formatter
-	name       string
-	processor  process.Processor
-	processing int32
+	batcher   common.Batcher[tracers.Span]
+	breaker   common.Breaker
+	client    *fasthttp.Client
+	config    configurer.Configuration
+	err       error
+	formatter tracers.Formatter
+	name      string
+	operator  tracers.OperatorManager
+	processor process.Processor
 }
 
-func New() tracers.Executor { return (&executor{}).init() }
+func New() tracers.Executor { return NewWith(tracers.Operator) }
+
+// NewWith
+// create and return executor bound to specified operator.
+func NewWith(operator tracers.OperatorManager) tracers.Executor {
+	return (&executor{config: operator.Config(), operator: operator}).init()
+}
 
 // /////////////////////////////////////////////////////////////////////////////
 // Interface methods
 // /////////////////////////////////////////////////////////////////////////////
 
+func (o *executor) Dropped() int64                      { return o.batcher.Dropped() }
+func (o *executor) Failed() int64                       { return o.batcher.Failed() }
+func (o *executor) Flush(ctx context.Context) error     { return o.batcher.Flush(ctx) }
 func (o *executor) Processor() process.Processor        { return o.processor }
-func (o *executor) Publish(spans ...tracers.Span) error { return o.publish(spans...) }
+func (o *executor) Publish(spans ...tracers.Span) error { return o.batcher.Publish(spans...) }
+func (o *executor) Redirected() int64                   { return o.batcher.Redirected() }
+func (o *executor) Remained() int                       { return o.batcher.Bucket().Count() }
+func (o *executor) Sent() int64                         { return o.batcher.Sent() }
 func (o *executor) SetFormatter(v tracers.Formatter)    { o.formatter = v }
 
 // /////////////////////////////////////////////////////////////////////////////
 // Event methods
 // /////////////////////////////////////////////////////////////////////////////
 
-func (o *executor) onAfter(ctx context.Context) (ignored bool) {
-	cc := atomic.LoadInt32(&o.processing)
-
-	// 处理完成.
-	// - 并行降低
-	// - 空数据桶.
-	if cc == 0 && o.bucket.IsEmpty() {
-		return
-	}
-
-	// 加大并行.
-	if cc < configurer.Config.GetBucketConcurrency() {
-		go o.pop()
-	}
-
-	// 定时延后.
-	time.Sleep(time.Millisecond * 100)
-	return o.onAfter(ctx)
-}
+func (o *executor) onAfter(ctx context.Context) (ignored bool) { return o.batcher.Drain(ctx) }
 
 func (o *executor) onCall(ctx context.Context) (ignored bool) {
-	common.InternalInfo("<%s> signal listening", o.name)
-
-	// 定时收取.
-	ti := time.NewTicker(time.Duration(configurer.Config.GetBucketFrequency()) * time.Millisecond)
-
-	// 监听信号.
-	for {
-		select {
-		case <-ti.C:
-			go o.pop()
-		case <-ctx.Done():
-			return
-		}
+	// Refuse to start
+	// if client not created.
+	if o.err != nil {
+		common.InternalInfo("<%s> start: %v", o.name, o.err)
+		return
 	}
+	return o.batcher.Listen(ctx)
 }
 
 func (o *executor) onPanic(_ context.Context, v interface{}) {
@@ -100,105 +87,60 @@ func (o *executor) onPanic(_ context.Context, v interface{}) {
 // /////////////////////////////////////////////////////////////////////////////
 
 func (o *executor) init() *executor {
-	o.bucket = common.NewBucket(configurer.Config.GetBucketCapacity())
-	o.formatter = (&formatter{}).init()
 	o.name = "tracer.jaeger"
+	o.breaker = common.NewBreaker(o.name, o.config.GetCircuitBreaker())
+	o.client, o.err = common.NewHttpClient(o.name, o.config.GetTLS(), func() string {
+		return o.config.GetJaegerTracer().GetEndpoint()
+	})
+	o.formatter = (&formatter{operator: o.operator}).init()
 	o.processor = process.New(o.name).
 		After(o.onAfter).
 		Callback(o.onCall).
 		Panic(o.onPanic)
+	o.batcher = common.NewBatcher[tracers.Span](o.name, tracers.NewBucket(o.name, o.operator),
+		o.config, o.processor.Healthy, o.send,
+	)
 
 	return o
 }
 
-func (o *executor) pop() {
-	// 限流控制.
-	if cc := atomic.AddInt32(&o.processing, 1); cc > configurer.Config.GetBucketConcurrency() {
-		atomic.AddInt32(&o.processing, -1)
+func (o *executor) send(ctx context.Context, spans ...tracers.Span) (err error) {
+	if len(spans) == 0 {
 		return
 	}
 
-	// 取出数据.
-	var (
-		list []tracers.Span
-		redo = false
-	)
-	if items, _, count := o.bucket.Popn(configurer.Config.GetBucketBatch()); count > 0 {
-		list = make([]tracers.Span, 0)
-		redo = true
-		// 遍历数据.
-		for _, item := range items {
-			if v, ok := item.(tracers.Span); ok {
-				list = append(list, v)
-			}
-		}
-		// 处理跨度.
-		if len(list) > 0 {
-			if err := o.send(list...); err != nil {
-				common.InternalInfo("<%s> send: %v", o.name, err)
-			}
-		}
-	}
-
-	// 恢复并行.
-	atomic.AddInt32(&o.processing, -1)
-	if redo {
-		o.pop()
+	// Give up
+	// if client not created.
+	if o.err != nil {
+		return tracers.DeadLetter(o.operator, o.name, o.err, spans...)
 	}
-}
-
-func (o *executor) publish(spans ...tracers.Span) (err error) {
-	var total int
 
-	// 健康进程.
-	if o.processor.Healthy() {
-		// 数据入桶.
-		for _, log := range spans {
-			if total, err = o.bucket.Add(log); err != nil {
-				return
-			}
-		}
-
-		// 立即消费.
-		if total >= configurer.Config.GetBucketBatch() {
-			go o.pop()
-		}
-		return
+	// Redirect to fallback
+	// if circuit breaker is open.
+	if !o.breaker.Allow() {
+		return tracers.Fallback(o.operator, o.name, common.ErrBreakerOpen, spans...)
 	}
 
-	// 立即写入.
-	return o.send(spans...)
+	return tracers.Chunk(o.formatter, o.config.GetMaxBatchBytes(), spans, func(body []byte, spans ...tracers.Span) error {
+		return o.post(ctx, body, spans...)
+	})
 }
 
-func (o *executor) send(spans ...tracers.Span) (err error) {
-	if len(spans) == 0 {
-		return
-	}
-
-	var body []byte
-
-	if body, err = o.formatter.Byte(spans...); err != nil {
-		return
-	}
-
-	var (
-		buf = bytes.NewBuffer(body)
-		req = fasthttp.AcquireRequest()
-		res = fasthttp.AcquireResponse()
-	)
-
-	req.SetRequestURI(configurer.Config.GetJaegerTracer().GetEndpoint())
-	req.SetBodyStream(buf, buf.Len())
-	req.Header.SetMethod(http.MethodPost)
-	req.Header.SetContentType(configurer.Config.GetJaegerTracer().GetContentType())
-
-	// Bind authorization.
-	if usr := configurer.Config.GetJaegerTracer().GetUsername(); usr != "" {
-		pwd := configurer.Config.GetJaegerTracer().GetPassword()
-		req.Header.Set("Authorization", fmt.Sprintf("Basic %s", base64.StdEncoding.EncodeToString([]byte(usr+":"+pwd))))
+func (o *executor) post(ctx context.Context, body []byte, spans ...tracers.Span) (err error) {
+	// Send request,
+	// retry if failed.
+	if err = common.HttpPost(ctx, o.client, o.config.GetHttpRetry(), o.config.GetJaegerTracer(), body, func(req *fasthttp.Request) {
+		// Bind basic authorization,
+		// take precedence over bearer token.
+		if usr := o.config.GetJaegerTracer().GetUsername(); usr != "" {
+			pwd := o.config.GetJaegerTracer().GetPassword()
+			req.Header.Set("Authorization", fmt.Sprintf("Basic %s", base64.StdEncoding.EncodeToString([]byte(usr+":"+pwd))))
+		}
+	}); err != nil {
+		o.breaker.Failure()
+		return tracers.DeadLetter(o.operator, o.name, err, spans...)
 	}
 
-	// Send request.
-	err = fasthttp.Do(req, res)
+	o.breaker.Success()
 	return
 }
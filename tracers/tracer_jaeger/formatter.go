@@ -19,7 +19,6 @@ import (
 	"context"
 	"encoding/binary"
 	"fmt"
-	"github.com/fuyibing/log/v5/configurer"
 	"github.com/fuyibing/log/v5/loggers"
 	"github.com/fuyibing/log/v5/tracers"
 	"github.com/fuyibing/log/v5/tracers/tracer_jaeger/jaeger"
@@ -28,7 +27,9 @@ import (
 )
 
 type (
-	formatter struct{}
+	formatter struct {
+		operator tracers.OperatorManager
+	}
 )
 
 func (o *formatter) Byte(vs ...tracers.Span) ([]byte, error)           { return o.thrift(vs...) }
@@ -62,8 +63,8 @@ func (o *formatter) buildLogs(list []loggers.Log) []*jaeger.Log {
 
 func (o *formatter) buildProcess() *jaeger.Process {
 	return &jaeger.Process{
-		ServiceName: configurer.Config.GetTracerTopic(),
-		Tags:        o.buildTagsMapper(tracers.Operator.GetResource()),
+		ServiceName: o.operator.Config().GetTracerTopic(),
+		Tags:        o.buildTagsMapper(o.operator.GetResource()),
 	}
 }
 
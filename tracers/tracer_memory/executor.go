@@ -0,0 +1,175 @@
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+//
+// author: wsfuyibing <websearch@163.com>
+// date: 2023-03-15
+
+// Package tracer_memory
+// 记录到内存中, 用于单元测试断言.
+package tracer_memory
+
+import (
+	"context"
+	"github.com/fuyibing/log/v5/common"
+	"github.com/fuyibing/log/v5/tracers"
+	"github.com/fuyibing/util/v8/process"
+	"sync"
+)
+
+type (
+	// Executor
+	// record published spans synchronously, expose query methods.
+	//
+	//   ex := tracer_memory.New()
+	//   log.Manager.Tracer().SetExecutor(ex)
+	//
+	//   // ... call handler
+	//
+	//   ex.FindByName("span name")
+	Executor interface {
+		tracers.Executor
+
+		// ByTraceId
+		// return recorded spans of specified trace.
+		ByTraceId(id tracers.TraceId) []tracers.Span
+
+		// FindByName
+		// return recorded spans with specified name.
+		FindByName(name string) []tracers.Span
+
+		// Reset
+		// clear recorded spans.
+		Reset()
+
+		// Spans
+		// return all recorded spans in published order.
+		Spans() []tracers.Span
+
+		// Tree
+		// build span tree of specified trace. Spans whose parent not
+		// recorded are returned as roots.
+		Tree(id tracers.TraceId) []*Node
+	}
+
+	// Node
+	// of span tree, children sorted by published order.
+	Node struct {
+		Children []*Node
+		Span     tracers.Span
+	}
+
+	executor struct {
+		sync.RWMutex
+
+		formatter tracers.Formatter
+		name      string
+		processor process.Processor
+		spans     []tracers.Span
+	}
+)
+
+func New() Executor { return (&executor{}).init() }
+
+// /////////////////////////////////////////////////////////////////////////////
+// Interface methods
+// /////////////////////////////////////////////////////////////////////////////
+
+func (o *executor) ByTraceId(id tracers.TraceId) []tracers.Span {
+	return o.find(func(v tracers.Span) bool { return v.Trace().TraceId() == id })
+}
+
+func (o *executor) FindByName(name string) []tracers.Span {
+	return o.find(func(v tracers.Span) bool { return v.Name() == name })
+}
+
+func (o *executor) Processor() process.Processor     { return o.processor }
+func (o *executor) SetFormatter(v tracers.Formatter) { o.formatter = v }
+
+func (o *executor) Publish(spans ...tracers.Span) error {
+	o.Lock()
+	defer o.Unlock()
+
+	o.spans = append(o.spans, spans...)
+	return nil
+}
+
+func (o *executor) Reset() {
+	o.Lock()
+	defer o.Unlock()
+
+	o.spans = nil
+}
+
+func (o *executor) Spans() []tracers.Span {
+	return o.find(func(_ tracers.Span) bool { return true })
+}
+
+func (o *executor) Tree(id tracers.TraceId) []*Node {
+	var (
+		list  = o.ByTraceId(id)
+		nodes = make(map[tracers.SpanId]*Node)
+		roots = make([]*Node, 0)
+	)
+
+	for _, v := range list {
+		nodes[v.SpanId()] = &Node{Span: v}
+	}
+
+	for _, v := range list {
+		node := nodes[v.SpanId()]
+		if parent, ok := nodes[v.ParentSpanId()]; ok && parent != node {
+			parent.Children = append(parent.Children, node)
+			continue
+		}
+		roots = append(roots, node)
+	}
+	return roots
+}
+
+// /////////////////////////////////////////////////////////////////////////////
+// Event methods
+// /////////////////////////////////////////////////////////////////////////////
+
+func (o *executor) onCall(ctx context.Context) (ignored bool) {
+	<-ctx.Done()
+	return
+}
+
+func (o *executor) onPanic(_ context.Context, v interface{}) {
+	common.InternalFatal("<%s> fatal: %v", o.name, v)
+}
+
+// /////////////////////////////////////////////////////////////////////////////
+// Access methods
+// /////////////////////////////////////////////////////////////////////////////
+
+func (o *executor) find(match func(v tracers.Span) bool) []tracers.Span {
+	o.RLock()
+	defer o.RUnlock()
+
+	list := make([]tracers.Span, 0)
+	for _, v := range o.spans {
+		if match(v) {
+			list = append(list, v)
+		}
+	}
+	return list
+}
+
+func (o *executor) init() *executor {
+	o.name = "tracer.memory"
+	o.processor = process.New(o.name).
+		Callback(o.onCall).
+		Panic(o.onPanic)
+
+	return o
+}
@@ -29,13 +29,20 @@ import (
 
 type executor struct {
 	bucket     common.Bucket
+	config     configurer.Configuration
 	formatter  tracers.Formatter
 	name       string
-	processor  process.Processor
 	processing int32
+	processor  process.Processor
 }
 
-func New() tracers.Executor { return (&executor{}).init() }
+func New() tracers.Executor { return NewWith(tracers.Operator) }
+
+// NewWith
+// create and return executor bound to specified operator.
+func NewWith(operator tracers.OperatorManager) tracers.Executor {
+	return (&executor{config: operator.Config()}).init()
+}
 
 // /////////////////////////////////////////////////////////////////////////////
 // Interface methods
@@ -73,7 +80,7 @@ func (o *executor) onPanic(_ context.Context, v interface{}) {
 // /////////////////////////////////////////////////////////////////////////////
 
 func (o *executor) init() *executor {
-	o.bucket = common.NewBucket(configurer.Config.GetBucketCapacity())
+	o.bucket = common.NewBucket(o.config.GetBucketCapacity())
 	o.formatter = (&formatter{}).init()
 	o.name = "tracer.term"
 	o.processor = process.New(o.name).
@@ -19,13 +19,14 @@ import (
 	"encoding/binary"
 	"encoding/json"
 	"fmt"
-	"github.com/fuyibing/log/v5/configurer"
 	"github.com/fuyibing/log/v5/loggers"
 	"github.com/fuyibing/log/v5/tracers"
 	"github.com/fuyibing/log/v5/tracers/tracer_zipkin/model"
 )
 
-type formatter struct{}
+type formatter struct {
+	operator tracers.OperatorManager
+}
 
 func (o *formatter) String(_ ...tracers.Span) (string, error) { return "", nil }
 
@@ -56,14 +57,14 @@ func (o *formatter) format(v tracers.Span) (sm *model.SpanModel) {
 		},
 		Name: v.Name(), Kind: model.Client,
 		Timestamp: v.StartTime(), Duration: v.Duration(),
-		LocalEndpoint: &model.Endpoint{ServiceName: configurer.Config.GetTracerTopic()},
+		LocalEndpoint: &model.Endpoint{ServiceName: o.operator.Config().GetTracerTopic()},
 	}
 
 	// 日志
 	sm.Annotations = o.genLogs(v.Logs()...)
 
 	// 标签
-	sm.Tags = o.genTags(tracers.Operator.GetResource(), v.Kv())
+	sm.Tags = o.genTags(o.operator.GetResource(), v.Kv())
 	return
 }
 